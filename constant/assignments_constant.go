@@ -2,4 +2,26 @@ package constant
 
 const (
 	ASSIGN_BY_PRODUCT_TYPE = "product type"
+	ASSIGN_BY_POSTAL_CODE  = "postal code"
+	ASSIGN_BY_RANKING      = "ranking"
+	ASSIGN_BY_BALANCE      = "balance"
+	ASSIGN_BY_NEAREST_OA   = "nearest oa"
+	ASSIGN_BY_MANUAL       = "manual"
+	ASSIGN_BY_RULE         = "rule"
+	ASSIGN_BY_TRANSFER     = "transfer"
+)
+
+const (
+	RELEASE_REASON_RECALL   = "RECALL"
+	RELEASE_REASON_TRANSFER = "TRANSFER"
+	// RELEASE_REASON_SLA_EXPIRED marks accounts the OA neither collected on
+	// nor contacted within the SLA
+	RELEASE_REASON_SLA_EXPIRED = "SLA_EXPIRED"
+)
+
+const (
+	UNASSIGNED_NO_ELIGIBLE_OA       = "NO_ELIGIBLE_OA"
+	UNASSIGNED_CAPACITY_EXHAUSTED   = "CAPACITY_EXHAUSTED"
+	UNASSIGNED_UNKNOWN_PRODUCT_TYPE = "UNKNOWN_PRODUCT_TYPE"
+	UNASSIGNED_NULL_DPD             = "NULL_DPD"
 )
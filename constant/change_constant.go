@@ -0,0 +1,6 @@
+package constant
+
+const (
+	CHANGE_TYPE_GAINED = "GAINED"
+	CHANGE_TYPE_LOST   = "LOST"
+)
@@ -0,0 +1,6 @@
+package constant
+
+const (
+	EXPERIMENT_ARM_CHAMPION   = "CHAMPION"
+	EXPERIMENT_ARM_CHALLENGER = "CHALLENGER"
+)
@@ -0,0 +1,19 @@
+package constant
+
+const (
+	JOB_NAME_ASSIGNMENT_RUN = "assignment run"
+	JOB_NAME_EXCEL_UPLOAD   = "excel upload"
+	JOB_NAME_SLA_RECALL     = "sla recall"
+	JOB_NAME_WEBHOOK_RETRY  = "webhook redelivery"
+)
+
+// ASSIGNMENTS_LOCK_KEY is the Postgres advisory lock taken by every job that
+// rewrites open assignments, so they run one after the other.
+const ASSIGNMENTS_LOCK_KEY = 7310001
+
+const (
+	JOB_STATUS_RUNNING = "RUNNING"
+	JOB_STATUS_SUCCESS = "SUCCESS"
+	JOB_STATUS_FAILED  = "FAILED"
+	JOB_STATUS_SKIPPED = "SKIPPED"
+)
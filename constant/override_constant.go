@@ -0,0 +1,10 @@
+package constant
+
+const (
+	// OVERRIDE_TYPE_PIN keeps the account with one OA
+	OVERRIDE_TYPE_PIN = "PIN"
+	// OVERRIDE_TYPE_LOCK keeps the account away from every OA
+	OVERRIDE_TYPE_LOCK = "LOCK"
+	// OVERRIDE_TYPE_EXCLUDE keeps the account away from one OA
+	OVERRIDE_TYPE_EXCLUDE = "EXCLUDE"
+)
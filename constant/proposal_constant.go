@@ -0,0 +1,7 @@
+package constant
+
+const (
+	PROPOSAL_STATUS_PENDING  = "PENDING"
+	PROPOSAL_STATUS_APPROVED = "APPROVED"
+	PROPOSAL_STATUS_REJECTED = "REJECTED"
+)
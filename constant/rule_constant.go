@@ -0,0 +1,22 @@
+package constant
+
+const (
+	RULE_FIELD_EARLY_OA      = "early_oa"
+	RULE_FIELD_SELF_CURED    = "self_cured"
+	RULE_FIELD_TOP_UP_SCORE  = "top_up_score"
+	RULE_FIELD_LOSS_ON_CLAIM = "loss_on_claim"
+)
+
+const (
+	RULE_OPERATOR_EQUAL     = "EQ"
+	RULE_OPERATOR_NOT_EQUAL = "NE"
+)
+
+const (
+	// RULE_ACTION_QUEUE keeps the account away from the OAs and parks it in
+	// the queue named by the rule target, e.g. IN_HOUSE or LEGAL
+	RULE_ACTION_QUEUE = "QUEUE"
+	// RULE_ACTION_OA_GROUP only lets OAs of the oa_group named by the rule
+	// target take the account
+	RULE_ACTION_OA_GROUP = "OA_GROUP"
+)
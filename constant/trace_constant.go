@@ -0,0 +1,19 @@
+package constant
+
+const (
+	TRACE_STEP_NULL_DPD = "NULL_DPD"
+	TRACE_STEP_OVERRIDE = "OVERRIDE"
+	TRACE_STEP_RULE     = "RULE_QUEUE"
+	TRACE_STEP_STICKY   = "STICKY"
+	TRACE_STEP_STRATEGY = "STRATEGY"
+)
+
+const (
+	REJECT_NOT_IN_OA_GROUP            = "NOT_IN_OA_GROUP"
+	REJECT_EXCLUDED_BY_OVERRIDE       = "EXCLUDED_BY_OVERRIDE"
+	REJECT_NO_PRODUCT_ALLOCATION      = "NO_PRODUCT_ALLOCATION"
+	REJECT_CAPACITY_EXHAUSTED         = "CAPACITY_EXHAUSTED"
+	REJECT_PRODUCT_CAPACITY_EXHAUSTED = "PRODUCT_CAPACITY_EXHAUSTED"
+	REJECT_NOT_PREFERRED_BY_STRATEGY  = "NOT_PREFERRED_BY_STRATEGY"
+	REJECT_PINNED_ELSEWHERE           = "PINNED_ELSEWHERE"
+)
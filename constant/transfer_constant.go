@@ -0,0 +1,15 @@
+package constant
+
+const (
+	// TRANSFER_TYPE_RECALL pulls the account back from its OA until the
+	// next run
+	TRANSFER_TYPE_RECALL = "RECALL"
+	// TRANSFER_TYPE_TRANSFER moves the account to another OA
+	TRANSFER_TYPE_TRANSFER = "TRANSFER"
+)
+
+const (
+	TRANSFER_STATUS_PENDING  = "PENDING"
+	TRANSFER_STATUS_APPROVED = "APPROVED"
+	TRANSFER_STATUS_REJECTED = "REJECTED"
+)
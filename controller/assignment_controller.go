@@ -2,15 +2,125 @@ package controller
 
 import (
 	"net/http"
+	"nhj-poc/constant"
+	"nhj-poc/domain/model"
 	"nhj-poc/service"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 func UpdateAssignmentsByProductType(c *gin.Context) {
-	if err := service.UpdateAssignmentsByProductType(); err != nil {
+	assignBy := c.DefaultQuery("assign_by", constant.ASSIGN_BY_PRODUCT_TYPE)
+	result, err := service.UpdateAssignmentsByProductType(assignBy, getAssignmentOptions(c))
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "worklist update successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "worklist update successfully",
+		"run_id":            result.RunID,
+		"bucket_counts":     result.BucketCounts,
+		"unassigned_counts": result.UnassignedCounts,
+		"max_imbalance":     result.MaxImbalance,
+	})
+}
+
+func UpdateAssignmentsByPostalCode(c *gin.Context) {
+	result, err := service.UpdateAssignmentsByPostalCode(getAssignmentOptions(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "worklist update successfully",
+		"run_id":             result.RunID,
+		"bucket_counts":      result.BucketCounts,
+		"unassigned_counts":  result.UnassignedCounts,
+		"uncovered_accounts": result.UncoveredAccounts,
+		"max_imbalance":      result.MaxImbalance,
+		"balance":            result.Balance,
+	})
+}
+
+func PreviewAssignments(c *gin.Context) {
+	assignBy := c.DefaultQuery("assign_by", constant.ASSIGN_BY_PRODUCT_TYPE)
+	preview, err := service.PreviewAssignments(assignBy, getAssignmentOptions(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+func getAssignmentOptions(c *gin.Context) model.AssignmentOptions {
+	return model.AssignmentOptions{
+		Sticky:          c.Query("sticky") == "true",
+		BalanceOverdue:  c.Query("balance_overdue") == "true",
+		GroupByCustomer: c.Query("group_by_customer") == "true",
+	}
+}
+
+func UpdateAssignments(c *gin.Context) {
+	assignBy := c.DefaultQuery("assign_by", constant.ASSIGN_BY_PRODUCT_TYPE)
+	result, err := service.UpdateAssignments(assignBy, getAssignmentOptions(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "worklist update successfully",
+		"run_id":             result.RunID,
+		"bucket_counts":      result.BucketCounts,
+		"unassigned_counts":  result.UnassignedCounts,
+		"uncovered_accounts": result.UncoveredAccounts,
+		"max_imbalance":      result.MaxImbalance,
+		"balance":            result.Balance,
+	})
+}
+
+func GetAssignmentStrategies(c *gin.Context) {
+	c.JSON(http.StatusOK, service.GetAssignmentStrategies())
+}
+
+func GetAssignmentHistory(c *gin.Context) {
+	var at *time.Time
+	if atStr := c.Query("at"); atStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", atStr, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'at' parameter"})
+			return
+		}
+		endOfDay := parsed.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		at = &endOfDay
+	}
+
+	history, err := service.GetAssignmentHistory(c.Param("id"), at)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+func GetUnassignedAccounts(c *gin.Context) {
+	unassigned, err := service.GetUnassignedAccounts(c.Query("reason"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, unassigned)
+}
+
+func GetAssignmentExplanation(c *gin.Context) {
+	assignment, trace, err := service.GetAssignmentExplanation(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"account_id": c.Param("id"),
+		"assignment": assignment,
+		"trace":      trace,
+	})
 }
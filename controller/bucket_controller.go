@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"net/http"
+	"nhj-poc/domain/api"
+	"nhj-poc/domain/model"
+	"nhj-poc/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+)
+
+func GetBuckets(c *gin.Context) {
+	buckets, err := service.GetBuckets()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, buckets)
+}
+
+func CreateBucket(c *gin.Context) {
+	var bAPI api.Bucket
+	if err := c.ShouldBindJSON(&bAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var bModel model.Bucket
+	if err := copier.Copy(&bModel, &bAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bucket, err := service.CreateBucket(bModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Bucket created successfully", "bucket": bucket})
+}
+
+func UpdateBucket(c *gin.Context) {
+	bucketID, err := strconv.Atoi(c.Param("bucket_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'bucket_id' parameter"})
+		return
+	}
+
+	var bAPI api.Bucket
+	if err := c.ShouldBindJSON(&bAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var bModel model.Bucket
+	if err := copier.Copy(&bModel, &bAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bucket, err := service.UpdateBucket(bucketID, bModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Bucket updated successfully", "bucket": bucket})
+}
+
+func DeleteBucket(c *gin.Context) {
+	bucketID, err := strconv.Atoi(c.Param("bucket_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'bucket_id' parameter"})
+		return
+	}
+
+	if err := service.DeleteBucket(bucketID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Bucket deleted successfully"})
+}
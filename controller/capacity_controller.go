@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/http"
+	"nhj-poc/domain/api"
+	"nhj-poc/domain/entity"
+	"nhj-poc/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetCapacityProposals(c *gin.Context) {
+	proposals, err := service.GetCapacityProposals(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, proposals)
+}
+
+func GenerateCapacityProposals(c *gin.Context) {
+	// Default to the previous month, the last one with a full month of
+	// transactions
+	period := time.Now().AddDate(0, -1, 0)
+	if periodStr := c.Query("period"); periodStr != "" {
+		parsed, err := time.ParseInLocation("2006-01", periodStr, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'period' parameter"})
+			return
+		}
+		period = parsed
+	}
+
+	proposals, err := service.GenerateCapacityProposals(period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Capacity proposals generated successfully", "proposals": proposals})
+}
+
+func ApproveCapacityProposal(c *gin.Context) {
+	decideCapacityProposal(c, service.ApproveCapacityProposal, "Capacity proposal approved successfully")
+}
+
+func RejectCapacityProposal(c *gin.Context) {
+	decideCapacityProposal(c, service.RejectCapacityProposal, "Capacity proposal rejected successfully")
+}
+
+func decideCapacityProposal(c *gin.Context, decide func(int, string) (*entity.CapacityProposal, error), message string) {
+	proposalID, err := strconv.Atoi(c.Param("proposal_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'proposal_id' parameter"})
+		return
+	}
+
+	var dAPI api.ProposalDecision
+	if err := c.ShouldBindJSON(&dAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	proposal, err := decide(proposalID, dAPI.DecidedBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "proposal": proposal})
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"net/http"
+	"nhj-poc/domain/api"
+	"nhj-poc/domain/model"
+	"nhj-poc/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+)
+
+// GetAssignmentChanges returns the accounts the OA gained or lost after
+// since, an RFC 3339 timestamp or a date, oldest first. after_id pages
+// through them: pass the last change_id received to get the next page.
+func GetAssignmentChanges(c *gin.Context) {
+	var since *time.Time
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			parsed, err = time.ParseInLocation("2006-01-02", sinceStr, time.Local)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'since' parameter"})
+			return
+		}
+		since = &parsed
+	}
+	afterID := 0
+	if afterIDStr := c.Query("after_id"); afterIDStr != "" {
+		parsed, err := strconv.Atoi(afterIDStr)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'after_id' parameter"})
+			return
+		}
+		afterID = parsed
+	}
+	limit := 500
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'limit' parameter"})
+			return
+		}
+		limit = parsed
+	}
+
+	changes, err := service.GetAssignmentChanges(c.Param("oa_id"), since, afterID, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+func UpdateOAWebhook(c *gin.Context) {
+	var wAPI api.OAWebhook
+	if err := c.ShouldBindJSON(&wAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var wModel model.OAWebhook
+	if err := copier.Copy(&wModel, &wAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := service.UpdateOAWebhook(c.Param("oa_id"), wModel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook updated successfully"})
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"net/http"
+	"nhj-poc/domain/api"
+	"nhj-poc/domain/model"
+	"nhj-poc/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+)
+
+func InsertContactLog(c *gin.Context) {
+	var cAPI api.ContactLog
+	if err := c.ShouldBindJSON(&cAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var cModel model.ContactLog
+	if err := copier.Copy(&cModel, &cAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contactLog, err := service.InsertContactLog(cModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Contact log created successfully", "contact_log": contactLog})
+}
+
+func GetContactLogs(c *gin.Context) {
+	contactLogs, err := service.GetContactLogs(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, contactLogs)
+}
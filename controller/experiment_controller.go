@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"net/http"
+	"nhj-poc/domain/api"
+	"nhj-poc/domain/model"
+	"nhj-poc/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+)
+
+func GetExperiments(c *gin.Context) {
+	experiments, err := service.GetExperiments()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, experiments)
+}
+
+func CreateExperiment(c *gin.Context) {
+	var eAPI api.Experiment
+	if err := c.ShouldBindJSON(&eAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var eModel model.Experiment
+	if err := copier.Copy(&eModel, &eAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	experiment, err := service.CreateExperiment(eModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Experiment created successfully", "experiment": experiment})
+}
+
+func StopExperiment(c *gin.Context) {
+	experimentID, err := strconv.Atoi(c.Param("experiment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'experiment_id' parameter"})
+		return
+	}
+
+	experiment, err := service.StopExperiment(experimentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Experiment stopped successfully", "experiment": experiment})
+}
+
+func GetExperimentResults(c *gin.Context) {
+	experimentID, err := strconv.Atoi(c.Param("experiment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'experiment_id' parameter"})
+		return
+	}
+
+	result, err := service.GetExperimentResults(experimentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"net/http"
+	"nhj-poc/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetJobRuns(c *gin.Context) {
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'limit' parameter"})
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := service.GetJobRuns(c.Query("job_name"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
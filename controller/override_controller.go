@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"net/http"
+	"nhj-poc/domain/api"
+	"nhj-poc/domain/model"
+	"nhj-poc/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+)
+
+func CreateOverride(c *gin.Context) {
+	var oAPI api.AssignmentOverride
+	if err := c.ShouldBindJSON(&oAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var oModel model.AssignmentOverride
+	if err := copier.Copy(&oModel, &oAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	override, err := service.CreateOverride(oModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Override created successfully", "override": override})
+}
+
+func GetOverrides(c *gin.Context) {
+	overrides, err := service.GetOverrides(c.Query("include_expired") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, overrides)
+}
+
+func DeleteOverride(c *gin.Context) {
+	overrideID, err := strconv.Atoi(c.Param("override_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'override_id' parameter"})
+		return
+	}
+
+	if err := service.DeleteOverride(overrideID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Override deleted successfully"})
+}
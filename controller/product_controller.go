@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"net/http"
+	"nhj-poc/domain/api"
+	"nhj-poc/domain/model"
+	"nhj-poc/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+)
+
+func GetProductTypes(c *gin.Context) {
+	productTypes, err := service.GetProductTypes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, productTypes)
+}
+
+func CreateProductType(c *gin.Context) {
+	var pAPI api.ProductType
+	if err := c.ShouldBindJSON(&pAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var pModel model.ProductType
+	if err := copier.Copy(&pModel, &pAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	productType, err := service.CreateProductType(pModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Product type created successfully", "product_type": productType})
+}
+
+func UpdateProductType(c *gin.Context) {
+	var pAPI api.ProductType
+	if err := c.ShouldBindJSON(&pAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var pModel model.ProductType
+	if err := copier.Copy(&pModel, &pAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	productType, err := service.UpdateProductType(c.Param("product_type"), pModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Product type updated successfully", "product_type": productType})
+}
+
+func GetOAAllocations(c *gin.Context) {
+	allocations, err := service.GetOAAllocations(c.Param("oa_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, allocations)
+}
+
+func ReplaceOAAllocations(c *gin.Context) {
+	var aAPIs []api.OAProductAllocation
+	if err := c.ShouldBindJSON(&aAPIs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var aModels []model.OAProductAllocation
+	if err := copier.Copy(&aModels, &aAPIs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	allocations, err := service.ReplaceOAAllocations(c.Param("oa_id"), aModels)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Allocations updated successfully", "allocations": allocations})
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"net/http"
+	"nhj-poc/domain/api"
+	"nhj-poc/domain/model"
+	"nhj-poc/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+)
+
+func GetRoutingRules(c *gin.Context) {
+	rules, err := service.GetRoutingRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+func CreateRoutingRule(c *gin.Context) {
+	var rAPI api.RoutingRule
+	if err := c.ShouldBindJSON(&rAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var rModel model.RoutingRule
+	if err := copier.Copy(&rModel, &rAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := service.CreateRoutingRule(rModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Routing rule created successfully", "rule": rule})
+}
+
+func UpdateRoutingRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("rule_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'rule_id' parameter"})
+		return
+	}
+
+	var rAPI api.RoutingRule
+	if err := c.ShouldBindJSON(&rAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var rModel model.RoutingRule
+	if err := copier.Copy(&rModel, &rAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := service.UpdateRoutingRule(ruleID, rModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Routing rule updated successfully", "rule": rule})
+}
+
+func DeleteRoutingRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("rule_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'rule_id' parameter"})
+		return
+	}
+
+	if err := service.DeleteRoutingRule(ruleID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Routing rule deleted successfully"})
+}
+
+func TestAssignmentRule(c *gin.Context) {
+	var tAPI api.RuleTest
+	if err := c.ShouldBindJSON(&tAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var aModel model.Account
+	if err := copier.Copy(&aModel, &tAPI.Account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var cModel model.Customer
+	if err := copier.Copy(&cModel, &tAPI.Customer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := service.TestRuleExpression(tAPI.Expression, aModel, cModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
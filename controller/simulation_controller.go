@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/http"
+	"nhj-poc/domain/api"
+	"nhj-poc/domain/model"
+	"nhj-poc/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+)
+
+func CreateAccountSnapshot(c *gin.Context) {
+	now := time.Now()
+	snapshotDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if dateStr := c.Query("snapshot_date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'snapshot_date' parameter"})
+			return
+		}
+		snapshotDate = parsed
+	}
+
+	count, err := service.CreateAccountSnapshot(snapshotDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Account snapshot created successfully", "snapshot_date": snapshotDate.Format("2006-01-02"), "account_count": count})
+}
+
+func GetAccountSnapshots(c *gin.Context) {
+	snapshots, err := service.GetAccountSnapshots()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, snapshots)
+}
+
+func SimulateAssignments(c *gin.Context) {
+	var sAPI api.Simulation
+	if err := c.ShouldBindJSON(&sAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+	snapshotDate, err := time.Parse("2006-01-02", sAPI.SnapshotDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'snapshot_date' field"})
+		return
+	}
+
+	var sModel model.Simulation
+	if err := copier.Copy(&sModel.Configurations, &sAPI.Configurations); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sModel.SnapshotDate = snapshotDate
+	sModel.AssignBy = sAPI.AssignBy
+	sModel.BalanceOverdue = sAPI.BalanceOverdue
+	sModel.GroupByCustomer = sAPI.GroupByCustomer
+
+	result, err := service.SimulateAssignments(sModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
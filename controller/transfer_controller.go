@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"net/http"
+	"nhj-poc/domain/api"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"nhj-poc/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+)
+
+func GetAssignmentTransfers(c *gin.Context) {
+	transfers, err := service.GetAssignmentTransfers(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+func RequestAssignmentTransfer(c *gin.Context) {
+	var tAPI api.AssignmentTransfer
+	if err := c.ShouldBindJSON(&tAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	var tModel model.AssignmentTransfer
+	if err := copier.Copy(&tModel, &tAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := service.RequestAssignmentTransfer(tModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Assignment transfer requested successfully", "transfer": transfer})
+}
+
+func ApproveAssignmentTransfer(c *gin.Context) {
+	decideAssignmentTransfer(c, service.ApproveAssignmentTransfer, "Assignment transfer approved successfully")
+}
+
+func RejectAssignmentTransfer(c *gin.Context) {
+	decideAssignmentTransfer(c, service.RejectAssignmentTransfer, "Assignment transfer rejected successfully")
+}
+
+func decideAssignmentTransfer(c *gin.Context, decide func(int, string) (*entity.AssignmentTransfer, error), message string) {
+	transferID, err := strconv.Atoi(c.Param("transfer_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for 'transfer_id' parameter"})
+		return
+	}
+
+	var dAPI api.ProposalDecision
+	if err := c.ShouldBindJSON(&dAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload: " + err.Error()})
+		return
+	}
+
+	transfer, err := decide(transferID, dAPI.DecidedBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "transfer": transfer})
+}
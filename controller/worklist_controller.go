@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"nhj-poc/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetOAWorklist(c *gin.Context) {
+	oaID := c.Param("oa_id")
+	file, err := service.GetOAWorklist(oaID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", oaID+"_worklist.xlsx"))
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", file)
+}
+
+func GetAllOAWorklists(c *gin.Context) {
+	file, err := service.GetAllOAWorklists()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="worklists.zip"`)
+	c.Data(http.StatusOK, "application/zip", file)
+}
@@ -0,0 +1,7 @@
+package api
+
+type Bucket struct {
+	BucketName *string `json:"bucket_name"`
+	MinDPD     int     `json:"min_dpd"`
+	MaxDPD     int     `json:"max_dpd"`
+}
@@ -0,0 +1,11 @@
+package api
+
+import "time"
+
+type ContactLog struct {
+	AccountID   string     `json:"account_id" binding:"required"`
+	OaID        string     `json:"oa_id" binding:"required"`
+	ContactType string     `json:"contact_type" binding:"required"`
+	Note        *string    `json:"note"`
+	ContactedAt *time.Time `json:"contacted_at"`
+}
@@ -0,0 +1,8 @@
+package api
+
+type Experiment struct {
+	ExperimentName       string  `json:"experiment_name"`
+	ChampionAssignBy     string  `json:"champion_assign_by"`
+	ChallengerAssignBy   string  `json:"challenger_assign_by"`
+	ChallengerPercentage float64 `json:"challenger_percentage"`
+}
@@ -0,0 +1,11 @@
+package api
+
+import "time"
+
+type AssignmentOverride struct {
+	AccountID    string     `json:"account_id"`
+	OverrideType string     `json:"override_type"`
+	OaID         *string    `json:"oa_id"`
+	Reason       string     `json:"reason"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,13 @@
+package api
+
+type ProductType struct {
+	ProductType string  `json:"product_type"`
+	ProductName *string `json:"product_name"`
+	Active      bool    `json:"active"`
+}
+
+type OAProductAllocation struct {
+	ProductType string  `json:"product_type" binding:"required"`
+	BucketID    *int    `json:"bucket_id"`
+	Percentage  float64 `json:"percentage"`
+}
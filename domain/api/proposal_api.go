@@ -0,0 +1,5 @@
+package api
+
+type ProposalDecision struct {
+	DecidedBy string `json:"decided_by" binding:"required"`
+}
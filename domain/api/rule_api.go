@@ -0,0 +1,47 @@
+package api
+
+type RoutingRule struct {
+	RuleName   string  `json:"rule_name"`
+	Priority   int     `json:"priority"`
+	Field      string  `json:"field"`
+	Operator   string  `json:"operator"`
+	Value      string  `json:"value"`
+	Expression *string `json:"expression"`
+	Action     string  `json:"action"`
+	Target     string  `json:"target"`
+	Active     bool    `json:"active"`
+}
+
+type RuleTestAccount struct {
+	AccountID         string  `json:"account_id"`
+	CustomerID        string  `json:"customer_id"`
+	ProductType       *string `json:"product_type"`
+	OutstandingAmount *int32  `json:"outstanding_amount"`
+	OverdueAmount     *int32  `json:"overdue_amount"`
+	DaysPastDue       *int32  `json:"days_past_due"`
+	SelfCured         *string `json:"self_cured"`
+	TopUpScore        *string `json:"top_up_score"`
+	LossOnSale        *int32  `json:"loss_on_sale"`
+	LossOnClaim       *string `json:"loss_on_claim"`
+	EarlyOA           *string `json:"early_oa"`
+}
+
+type RuleTestCustomer struct {
+	CustomerID         string  `json:"customer_id"`
+	CustomerName       *string `json:"customer_name"`
+	OccupationID       *int32  `json:"occupation_id"`
+	RegisterTambol     *string `json:"register_tambol"`
+	RegisterAmphur     *string `json:"register_amphur"`
+	RegisterProvince   *string `json:"register_province"`
+	RegisterPostalCode *string `json:"register_postal_code"`
+	CurrentTambol      *string `json:"current_tambol"`
+	CurrentAmphur      *string `json:"current_amphur"`
+	CurrentProvince    *string `json:"current_province"`
+	CurrentPostalCode  *string `json:"current_postal_code"`
+}
+
+type RuleTest struct {
+	Expression string           `json:"expression" binding:"required"`
+	Account    RuleTestAccount  `json:"account"`
+	Customer   RuleTestCustomer `json:"customer"`
+}
@@ -0,0 +1,39 @@
+package api
+
+type SimulationOABucket struct {
+	BucketID    int                `json:"bucket_id" binding:"required,gt=0"`
+	Allocations map[string]float64 `json:"allocations"`
+}
+
+// SimulationOA overrides the stored OA with the same oa_id; fields left out
+// keep their stored value.
+type SimulationOA struct {
+	OAId        string               `json:"oa_id" binding:"required"`
+	Capacity    *int                 `json:"capacity"`
+	Ranking     *int                 `json:"ranking"`
+	Allocations map[string]float64   `json:"allocations"`
+	PostalList  *string              `json:"postal_list"`
+	OAGroup     *string              `json:"oa_group"`
+	Buckets     []SimulationOABucket `json:"buckets" binding:"dive"`
+}
+
+type SimulationBucket struct {
+	BucketID   int     `json:"bucket_id" binding:"required,gt=0"`
+	BucketName *string `json:"bucket_name"`
+	MinDPD     int     `json:"min_dpd"`
+	MaxDPD     int     `json:"max_dpd"`
+}
+
+type SimulationConfig struct {
+	Name    string             `json:"name" binding:"required"`
+	OAs     []SimulationOA     `json:"oas" binding:"dive"`
+	Buckets []SimulationBucket `json:"buckets" binding:"dive"`
+}
+
+type Simulation struct {
+	SnapshotDate    string             `json:"snapshot_date" binding:"required"`
+	AssignBy        string             `json:"assign_by"`
+	BalanceOverdue  bool               `json:"balance_overdue"`
+	GroupByCustomer bool               `json:"group_by_customer"`
+	Configurations  []SimulationConfig `json:"configurations" binding:"required,min=1,dive"`
+}
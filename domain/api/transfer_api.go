@@ -0,0 +1,10 @@
+package api
+
+// AssignmentTransfer recalls the account when to_oa_id is left out and moves
+// it to to_oa_id otherwise.
+type AssignmentTransfer struct {
+	AccountID   string  `json:"account_id" binding:"required"`
+	ToOaID      *string `json:"to_oa_id"`
+	Reason      string  `json:"reason" binding:"required"`
+	RequestedBy string  `json:"requested_by" binding:"required"`
+}
@@ -0,0 +1,6 @@
+package api
+
+type OAWebhook struct {
+	WebhookURL    string `json:"webhook_url" binding:"required,url"`
+	WebhookSecret string `json:"webhook_secret" binding:"required,min=16"`
+}
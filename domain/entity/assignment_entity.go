@@ -1,14 +1,39 @@
 package entity
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+)
 
 type Assignments struct {
-	AssignmentsID int             `gorm:"primaryKey;autoIncrement;not null" json:"assignments_id"`
-	AccountID     *sql.NullString `gorm:"column:account_id" json:"account_id"`
-	OaID          *sql.NullString `gorm:"column:oa_id" json:"oa_id"`
-	AssignBy      *sql.NullString `gorm:"column:assign_by" json:"assign_by"`
+	AssignmentsID int              `gorm:"primaryKey;autoIncrement;not null" json:"assignments_id"`
+	AccountID     *sql.NullString  `gorm:"column:account_id" json:"account_id"`
+	OaID          *sql.NullString  `gorm:"column:oa_id" json:"oa_id"`
+	AssignBy      *sql.NullString  `gorm:"column:assign_by" json:"assign_by"`
+	AssignedAt    *time.Time       `gorm:"column:assigned_at" json:"assigned_at"`
+	ReleasedAt    *time.Time       `gorm:"column:released_at" json:"released_at"`
+	ReleaseReason *sql.NullString  `gorm:"column:release_reason" json:"release_reason"`
+	RunID         *sql.NullString  `gorm:"column:run_id" json:"run_id"`
+	Queue         *sql.NullString  `gorm:"column:queue" json:"queue"`
+	RuleID        *sql.NullInt32   `gorm:"column:rule_id" json:"rule_id"`
+	DistanceKm    *sql.NullFloat64 `gorm:"column:distance_km" json:"distance_km"`
+	ExperimentID  *sql.NullInt32   `gorm:"column:experiment_id" json:"experiment_id"`
+	Arm           *sql.NullString  `gorm:"column:arm" json:"arm"`
 }
 
 func (Assignments) TableName() string {
 	return "assignments"
 }
+
+type UnassignedAccount struct {
+	UnassignedID int             `gorm:"column:unassigned_id;primaryKey;autoIncrement;not null" json:"unassigned_id"`
+	AccountID    string          `gorm:"column:account_id;not null" json:"account_id"`
+	Reason       string          `gorm:"column:reason;not null" json:"reason"`
+	AssignBy     *sql.NullString `gorm:"column:assign_by" json:"assign_by"`
+	RunID        *sql.NullString `gorm:"column:run_id" json:"run_id"`
+	CreatedAt    time.Time       `gorm:"column:created_at;not null" json:"created_at"`
+}
+
+func (UnassignedAccount) TableName() string {
+	return "unassigned_account"
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "database/sql"
+
+type Bucket struct {
+	BucketID   int             `gorm:"column:bucket_id;primaryKey;autoIncrement;not null" json:"bucket_id"`
+	BucketName *sql.NullString `gorm:"column:bucket_name" json:"bucket_name"`
+	MinDPD     int             `gorm:"column:min_dpd;not null" json:"min_dpd"`
+	MaxDPD     int             `gorm:"column:max_dpd;not null" json:"max_dpd"`
+}
+
+func (Bucket) TableName() string {
+	return "bucket"
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AssignmentChange is one account an OA gained or lost.
+type AssignmentChange struct {
+	ChangeID    int             `gorm:"column:change_id;primaryKey;autoIncrement;not null" json:"change_id"`
+	OaID        string          `gorm:"column:oa_id;not null" json:"oa_id"`
+	AccountID   string          `gorm:"column:account_id;not null" json:"account_id"`
+	ChangeType  string          `gorm:"column:change_type;not null" json:"change_type"`
+	RunID       *sql.NullString `gorm:"column:run_id" json:"run_id"`
+	CreatedAt   time.Time       `gorm:"column:created_at;not null" json:"created_at"`
+	DeliveredAt *time.Time      `gorm:"column:delivered_at" json:"delivered_at"`
+}
+
+func (AssignmentChange) TableName() string {
+	return "assignment_change"
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ContactLog is one attempt by an OA to reach the customer of an account.
+type ContactLog struct {
+	ContactID   int             `gorm:"column:contact_id;primaryKey;autoIncrement;not null" json:"contact_id"`
+	AccountID   string          `gorm:"column:account_id;not null" json:"account_id"`
+	OaID        string          `gorm:"column:oa_id;not null" json:"oa_id"`
+	ContactType string          `gorm:"column:contact_type;not null" json:"contact_type"`
+	Note        *sql.NullString `gorm:"column:note" json:"note"`
+	ContactedAt time.Time       `gorm:"column:contacted_at;not null" json:"contacted_at"`
+}
+
+func (ContactLog) TableName() string {
+	return "contact_log"
+}
@@ -14,6 +14,15 @@ type Account struct {
 	LossOnSale        *sql.NullInt32  `gorm:"column:loss_on_sale" json:"loss_on_sale"`
 	LossOnClaim       *sql.NullString `gorm:"column:loss_on_claim" json:"loss_on_claim"`
 	EarlyOA           *sql.NullString `gorm:"column:early_oa" json:"early_oa"`
+	// Members are the customer's other accounts this account stands for when
+	// the engine assigns by customer
+	Members []Account `gorm:"-" json:"-"`
+	// GroupOutstanding and GroupOverdue are the amounts of the whole group
+	// the account leads, too large for the account's own columns
+	GroupOutstanding int64 `gorm:"-" json:"-"`
+	GroupOverdue     int64 `gorm:"-" json:"-"`
+	// ExcludedOaID is the OA an EXCLUDE override keeps the account away from
+	ExcludedOaID string `gorm:"-" json:"-"`
 }
 
 func (Account) TableName() string {
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type Experiment struct {
+	ExperimentID         int        `gorm:"column:experiment_id;primaryKey;autoIncrement;not null" json:"experiment_id"`
+	ExperimentName       string     `gorm:"column:experiment_name;not null" json:"experiment_name"`
+	ChampionAssignBy     string     `gorm:"column:champion_assign_by;not null" json:"champion_assign_by"`
+	ChallengerAssignBy   string     `gorm:"column:challenger_assign_by;not null" json:"challenger_assign_by"`
+	ChallengerPercentage float64    `gorm:"column:challenger_percentage;not null" json:"challenger_percentage"`
+	Active               bool       `gorm:"column:active;not null" json:"active"`
+	StartedAt            time.Time  `gorm:"column:started_at;not null" json:"started_at"`
+	EndedAt              *time.Time `gorm:"column:ended_at" json:"ended_at"`
+}
+
+func (Experiment) TableName() string {
+	return "experiment"
+}
+
+// ExperimentArmAmount is one row of the experiment outcome summed per arm.
+type ExperimentArmAmount struct {
+	Arm          string `gorm:"column:arm"`
+	AccountCount int64  `gorm:"column:account_count"`
+	Amount       int64  `gorm:"column:amount"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+type JobRun struct {
+	JobRunID        int             `gorm:"column:job_run_id;primaryKey;autoIncrement;not null" json:"job_run_id"`
+	JobName         string          `gorm:"column:job_name;not null" json:"job_name"`
+	Status          string          `gorm:"column:status;not null" json:"status"`
+	StartedAt       time.Time       `gorm:"column:started_at;not null" json:"started_at"`
+	FinishedAt      *time.Time      `gorm:"column:finished_at" json:"finished_at"`
+	AssignedCount   *sql.NullInt32  `gorm:"column:assigned_count" json:"assigned_count"`
+	UnassignedCount *sql.NullInt32  `gorm:"column:unassigned_count" json:"unassigned_count"`
+	RunID           *sql.NullString `gorm:"column:run_id" json:"run_id"`
+	Error           *sql.NullString `gorm:"column:error" json:"error"`
+}
+
+func (JobRun) TableName() string {
+	return "job_run"
+}
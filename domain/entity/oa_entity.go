@@ -13,11 +13,29 @@ type OA struct {
 	C2CPercentage          *sql.NullFloat64 `gorm:"column:c2c_percentage" json:"c2c_percentage"`
 	CRLPercentage          *sql.NullFloat64 `gorm:"column:crl_percentage" json:"crl_percentage"`
 	PostalList             *sql.NullString  `gorm:"column:postal_list" json:"postal_list"`
+	OAGroup                *sql.NullString  `gorm:"column:oa_group" json:"oa_group"`
 	LocationLatitude       *sql.NullFloat64 `gorm:"column:location_latitude" json:"location_latitude"`
 	LocationLongitude      *sql.NullFloat64 `gorm:"column:location_longitude" json:"location_longitude"`
 	LocationUpdateDateTime *time.Time       `gorm:"column:location_update_datetime" json:"location_update_datetime"`
+	WebhookURL             *sql.NullString  `gorm:"column:webhook_url" json:"webhook_url"`
+	WebhookSecret          *sql.NullString  `gorm:"column:webhook_secret" json:"-"`
+	// Allocations holds the percentage of each product type the OA takes,
+	// resolved by the assignment engine from oa_product_allocation and the
+	// legacy c2c/crl columns
+	Allocations map[string]float64 `gorm:"-" json:"-"`
 }
 
 func (OA) TableName() string {
 	return "oa"
 }
+
+type OABucket struct {
+	OAId          string           `gorm:"column:oa_id" json:"oa_id"`
+	BucketID      int              `gorm:"column:bucket_id" json:"bucket_id"`
+	C2CPercentage *sql.NullFloat64 `gorm:"column:c2c_percentage" json:"c2c_percentage"`
+	CRLPercentage *sql.NullFloat64 `gorm:"column:crl_percentage" json:"crl_percentage"`
+}
+
+func (OABucket) TableName() string {
+	return "oa_bucket"
+}
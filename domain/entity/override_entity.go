@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+type AssignmentOverride struct {
+	OverrideID   int             `gorm:"column:override_id;primaryKey;autoIncrement;not null" json:"override_id"`
+	AccountID    string          `gorm:"column:account_id;not null" json:"account_id"`
+	OverrideType string          `gorm:"column:override_type;not null" json:"override_type"`
+	OaID         *sql.NullString `gorm:"column:oa_id" json:"oa_id"`
+	Reason       string          `gorm:"column:reason;not null" json:"reason"`
+	ExpiresAt    *time.Time      `gorm:"column:expires_at" json:"expires_at"`
+	CreatedAt    time.Time       `gorm:"column:created_at;not null" json:"created_at"`
+	// TransferID is the approved transfer or recall that created the override
+	TransferID *sql.NullInt32 `gorm:"column:transfer_id" json:"transfer_id"`
+}
+
+func (AssignmentOverride) TableName() string {
+	return "assignment_override"
+}
@@ -0,0 +1,11 @@
+package entity
+
+type PostalCodeCentroid struct {
+	PostalCode string  `gorm:"column:postal_code;primaryKey;not null" json:"postal_code"`
+	Latitude   float64 `gorm:"column:latitude;not null" json:"latitude"`
+	Longitude  float64 `gorm:"column:longitude;not null" json:"longitude"`
+}
+
+func (PostalCodeCentroid) TableName() string {
+	return "postal_code_centroid"
+}
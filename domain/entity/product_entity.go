@@ -0,0 +1,28 @@
+package entity
+
+import "database/sql"
+
+type ProductType struct {
+	ProductType string          `gorm:"column:product_type;primaryKey;not null" json:"product_type"`
+	ProductName *sql.NullString `gorm:"column:product_name" json:"product_name"`
+	Active      bool            `gorm:"column:active;not null" json:"active"`
+}
+
+func (ProductType) TableName() string {
+	return "product_type"
+}
+
+// OAProductAllocation is the share of a product's accounts an OA takes. A row
+// without a bucket applies to every bucket the OA takes; a row with a bucket
+// overrides it for that bucket.
+type OAProductAllocation struct {
+	AllocationID int            `gorm:"column:allocation_id;primaryKey;autoIncrement;not null" json:"allocation_id"`
+	OAId         string         `gorm:"column:oa_id;not null" json:"oa_id"`
+	ProductType  string         `gorm:"column:product_type;not null" json:"product_type"`
+	BucketID     *sql.NullInt32 `gorm:"column:bucket_id" json:"bucket_id"`
+	Percentage   float64        `gorm:"column:percentage;not null" json:"percentage"`
+}
+
+func (OAProductAllocation) TableName() string {
+	return "oa_product_allocation"
+}
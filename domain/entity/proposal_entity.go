@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+type CapacityProposal struct {
+	ProposalID         int              `gorm:"column:proposal_id;primaryKey;autoIncrement;not null" json:"proposal_id"`
+	OaID               string           `gorm:"column:oa_id;not null" json:"oa_id"`
+	Period             string           `gorm:"column:period;not null" json:"period"`
+	ProductType        string           `gorm:"column:product_type;not null" json:"product_type"`
+	BucketID           *sql.NullInt32   `gorm:"column:bucket_id" json:"bucket_id"`
+	CollectionRate     *sql.NullFloat64 `gorm:"column:collection_rate" json:"collection_rate"`
+	CurrentPercentage  *sql.NullFloat64 `gorm:"column:current_percentage" json:"current_percentage"`
+	ProposedPercentage *sql.NullFloat64 `gorm:"column:proposed_percentage" json:"proposed_percentage"`
+	Status             string           `gorm:"column:status;not null" json:"status"`
+	CreatedAt          time.Time        `gorm:"column:created_at;not null" json:"created_at"`
+	DecidedAt          *time.Time       `gorm:"column:decided_at" json:"decided_at"`
+	DecidedBy          *sql.NullString  `gorm:"column:decided_by" json:"decided_by"`
+}
+
+func (CapacityProposal) TableName() string {
+	return "capacity_proposal"
+}
+
+// OAProductAmount is one row of an amount summed per OA and product type.
+type OAProductAmount struct {
+	OaID        string `gorm:"column:oa_id"`
+	ProductType string `gorm:"column:product_type"`
+	Amount      int64  `gorm:"column:amount"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "database/sql"
+
+type RoutingRule struct {
+	RuleID     int             `gorm:"column:rule_id;primaryKey;autoIncrement;not null" json:"rule_id"`
+	RuleName   string          `gorm:"column:rule_name;not null" json:"rule_name"`
+	Priority   int             `gorm:"column:priority;not null" json:"priority"`
+	Field      string          `gorm:"column:field;not null" json:"field"`
+	Operator   string          `gorm:"column:operator;not null" json:"operator"`
+	Value      string          `gorm:"column:value;not null" json:"value"`
+	Expression *sql.NullString `gorm:"column:expression" json:"expression"`
+	Action     string          `gorm:"column:action;not null" json:"action"`
+	Target     string          `gorm:"column:target;not null" json:"target"`
+	Active     bool            `gorm:"column:active;not null" json:"active"`
+}
+
+func (RoutingRule) TableName() string {
+	return "routing_rule"
+}
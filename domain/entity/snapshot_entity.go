@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// AccountSnapshot is a copy of an account row as it was on SnapshotDate.
+type AccountSnapshot struct {
+	SnapshotDate time.Time `gorm:"column:snapshot_date;type:date;not null" json:"snapshot_date"`
+	Account      `gorm:"embedded"`
+}
+
+func (AccountSnapshot) TableName() string {
+	return "account_snapshot"
+}
+
+type SnapshotSummary struct {
+	SnapshotDate time.Time `gorm:"column:snapshot_date" json:"snapshot_date"`
+	AccountCount int64     `gorm:"column:account_count" json:"account_count"`
+}
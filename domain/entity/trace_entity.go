@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AssignmentTrace records how the latest run decided on one account.
+type AssignmentTrace struct {
+	TraceID   int             `gorm:"column:trace_id;primaryKey;autoIncrement;not null" json:"trace_id"`
+	AccountID string          `gorm:"column:account_id;not null" json:"account_id"`
+	RunID     *sql.NullString `gorm:"column:run_id" json:"run_id"`
+	BucketID  *sql.NullInt32  `gorm:"column:bucket_id" json:"bucket_id"`
+	AssignBy  string          `gorm:"column:assign_by;not null" json:"assign_by"`
+	Step      string          `gorm:"column:step;not null" json:"step"`
+	RuleID    *sql.NullInt32  `gorm:"column:rule_id" json:"rule_id"`
+	Queue     *sql.NullString `gorm:"column:queue" json:"queue"`
+	OAGroup   *sql.NullString `gorm:"column:oa_group" json:"oa_group"`
+	// Position is the order the account was allocated in within its bucket
+	// and oa_group
+	Position *sql.NullInt32 `gorm:"column:position" json:"position"`
+	// LeadAccountID is set when the account followed the lead account of its
+	// customer
+	LeadAccountID *sql.NullString  `gorm:"column:lead_account_id" json:"lead_account_id"`
+	OaID          *sql.NullString  `gorm:"column:oa_id" json:"oa_id"`
+	Reason        *sql.NullString  `gorm:"column:reason" json:"reason"`
+	Candidates    []TraceCandidate `gorm:"column:candidates;serializer:json" json:"candidates"`
+	CreatedAt     time.Time        `gorm:"column:created_at;not null" json:"created_at"`
+	// Stale is set when the account was moved after the run, by a transfer,
+	// a recall or the SLA, so the trace no longer explains where it is
+	Stale bool `gorm:"-" json:"stale"`
+}
+
+func (AssignmentTrace) TableName() string {
+	return "assignment_trace"
+}
+
+// TraceCandidate is one OA of the bucket as the engine saw it when it
+// allocated the account.
+type TraceCandidate struct {
+	OaID              string  `json:"oa_id"`
+	Percentage        float64 `json:"percentage"`
+	RemainingCapacity int     `json:"remaining_capacity"`
+	ProductCapacity   int     `json:"product_capacity"`
+	Selected          bool    `json:"selected"`
+	RejectReason      string  `json:"reject_reason,omitempty"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AssignmentTransfer is a request to recall an account from its OA or move it
+// to another one. It takes effect once a supervisor approves it.
+type AssignmentTransfer struct {
+	TransferID   int             `gorm:"column:transfer_id;primaryKey;autoIncrement;not null" json:"transfer_id"`
+	AccountID    string          `gorm:"column:account_id;not null" json:"account_id"`
+	TransferType string          `gorm:"column:transfer_type;not null" json:"transfer_type"`
+	FromOaID     string          `gorm:"column:from_oa_id;not null" json:"from_oa_id"`
+	ToOaID       *sql.NullString `gorm:"column:to_oa_id" json:"to_oa_id"`
+	Reason       string          `gorm:"column:reason;not null" json:"reason"`
+	Status       string          `gorm:"column:status;not null" json:"status"`
+	RequestedBy  string          `gorm:"column:requested_by;not null" json:"requested_by"`
+	RequestedAt  time.Time       `gorm:"column:requested_at;not null" json:"requested_at"`
+	DecidedBy    *sql.NullString `gorm:"column:decided_by" json:"decided_by"`
+	DecidedAt    *time.Time      `gorm:"column:decided_at" json:"decided_at"`
+}
+
+func (AssignmentTransfer) TableName() string {
+	return "assignment_transfer"
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// WorklistRow is one open assignment joined with the account, the customer
+// and the account's latest payment plan.
+type WorklistRow struct {
+	OaID               string           `gorm:"column:oa_id"`
+	AccountID          string           `gorm:"column:account_id"`
+	CustomerID         string           `gorm:"column:customer_id"`
+	CustomerName       *sql.NullString  `gorm:"column:customer_name"`
+	OccupationName     *sql.NullString  `gorm:"column:occupation_name"`
+	RegisterAddress    *sql.NullString  `gorm:"column:register_address"`
+	RegisterTambol     *sql.NullString  `gorm:"column:register_tambol"`
+	RegisterAmphur     *sql.NullString  `gorm:"column:register_amphur"`
+	RegisterProvince   *sql.NullString  `gorm:"column:register_province"`
+	RegisterPostalCode *sql.NullString  `gorm:"column:register_postal_code"`
+	CurrentAddress     *sql.NullString  `gorm:"column:current_address"`
+	CurrentTambol      *sql.NullString  `gorm:"column:current_tambol"`
+	CurrentAmphur      *sql.NullString  `gorm:"column:current_amphur"`
+	CurrentProvince    *sql.NullString  `gorm:"column:current_province"`
+	CurrentPostalCode  *sql.NullString  `gorm:"column:current_postal_code"`
+	ProductType        *sql.NullString  `gorm:"column:product_type"`
+	DaysPastDue        *sql.NullInt32   `gorm:"column:days_past_due"`
+	OutstandingAmount  *sql.NullInt32   `gorm:"column:outstanding_amount"`
+	OverdueAmount      *sql.NullInt32   `gorm:"column:overdue_amount"`
+	PaymentTitle       *sql.NullString  `gorm:"column:payment_title"`
+	PaymentDueDate     *time.Time       `gorm:"column:payment_due_date"`
+	PaymentStatusID    *sql.NullInt32   `gorm:"column:payment_status_id"`
+	AssignedAt         *time.Time       `gorm:"column:assigned_at"`
+	DistanceKm         *sql.NullFloat64 `gorm:"column:distance_km"`
+}
@@ -0,0 +1,77 @@
+package model
+
+type UncoveredAccount struct {
+	AccountID  string `json:"account_id"`
+	PostalCode string `json:"postal_code"`
+}
+
+type OAPreview struct {
+	OAId             string  `json:"oa_id"`
+	AccountCount     int     `json:"account_count"`
+	TotalOutstanding int64   `json:"total_outstanding"`
+	Capacity         int     `json:"capacity"`
+	Utilisation      float64 `json:"utilisation"`
+}
+
+type AssignmentChange struct {
+	AccountID   string `json:"account_id"`
+	CurrentOaID string `json:"current_oa_id"`
+	NewOaID     string `json:"new_oa_id"`
+}
+
+type AssignmentPreview struct {
+	AssignBy          string              `json:"assign_by"`
+	BucketCounts      map[int]int         `json:"bucket_counts"`
+	OAs               []OAPreview         `json:"oas"`
+	Changes           []AssignmentChange  `json:"changes"`
+	UncoveredAccounts []UncoveredAccount  `json:"uncovered_accounts"`
+	Unassigned        []UnassignedAccount `json:"unassigned"`
+	Balance           []OABalance         `json:"balance"`
+	MaxImbalance      float64             `json:"max_imbalance"`
+}
+
+type AssignmentOptions struct {
+	// Sticky keeps accounts with their current OA while the OA is still
+	// eligible and has capacity
+	Sticky bool
+	// BalanceOverdue makes the balance strategy weigh overdue amount as well
+	// as outstanding amount
+	BalanceOverdue bool
+	// GroupByCustomer assigns all the accounts of a customer to one OA
+	GroupByCustomer bool
+}
+
+type AssignmentStrategy struct {
+	AssignBy    string `json:"assign_by"`
+	Description string `json:"description"`
+}
+
+type UnassignedAccount struct {
+	AccountID string `json:"account_id"`
+	Reason    string `json:"reason"`
+}
+
+type AssignmentResult struct {
+	RunID             string             `json:"run_id"`
+	BucketCounts      map[int]int        `json:"bucket_counts"`
+	UnassignedCounts  map[string]int     `json:"unassigned_counts"`
+	UncoveredAccounts []UncoveredAccount `json:"uncovered_accounts"`
+	Balance           []OABalance        `json:"balance"`
+	MaxImbalance      float64            `json:"max_imbalance"`
+}
+
+// OABalance compares the share of money an OA got in one bucket and product
+// with the share its percentage entitles it to.
+type OABalance struct {
+	BucketID         int     `json:"bucket_id"`
+	ProductType      string  `json:"product_type"`
+	OAId             string  `json:"oa_id"`
+	TargetShare      float64 `json:"target_share"`
+	TotalOutstanding int64   `json:"total_outstanding"`
+	OutstandingShare float64 `json:"outstanding_share"`
+	TotalOverdue     int64   `json:"total_overdue"`
+	OverdueShare     float64 `json:"overdue_share"`
+	// Imbalance is the larger gap between the outstanding or overdue share
+	// and the target share
+	Imbalance float64 `json:"imbalance"`
+}
@@ -0,0 +1,7 @@
+package model
+
+type Bucket struct {
+	BucketName *string
+	MinDPD     int
+	MaxDPD     int
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type ContactLog struct {
+	AccountID   string
+	OaID        string
+	ContactType string
+	Note        *string
+	ContactedAt *time.Time
+}
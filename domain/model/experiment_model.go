@@ -0,0 +1,25 @@
+package model
+
+type Experiment struct {
+	ExperimentName       string
+	ChampionAssignBy     string
+	ChallengerAssignBy   string
+	ChallengerPercentage float64
+}
+
+// ExperimentArmResult is the collection outcome of one arm of an experiment.
+type ExperimentArmResult struct {
+	Arm            string  `json:"arm"`
+	AssignBy       string  `json:"assign_by"`
+	AccountCount   int64   `json:"account_count"`
+	OverdueAmount  int64   `json:"overdue_amount"`
+	Collected      int64   `json:"collected"`
+	CollectionRate float64 `json:"collection_rate"`
+}
+
+type ExperimentResult struct {
+	ExperimentID int                   `json:"experiment_id"`
+	Arms         []ExperimentArmResult `json:"arms"`
+	// Uplift is the challenger collection rate minus the champion one
+	Uplift float64 `json:"uplift"`
+}
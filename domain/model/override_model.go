@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type AssignmentOverride struct {
+	AccountID    string
+	OverrideType string
+	OaID         *string
+	Reason       string
+	ExpiresAt    *time.Time
+}
@@ -0,0 +1,13 @@
+package model
+
+type ProductType struct {
+	ProductType string
+	ProductName *string
+	Active      bool
+}
+
+type OAProductAllocation struct {
+	ProductType string
+	BucketID    *int
+	Percentage  float64
+}
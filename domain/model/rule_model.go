@@ -0,0 +1,19 @@
+package model
+
+type RoutingRule struct {
+	RuleName   string
+	Priority   int
+	Field      string
+	Operator   string
+	Value      string
+	Expression *string
+	Action     string
+	Target     string
+	Active     bool
+}
+
+type RuleTestResult struct {
+	Matched bool   `json:"matched"`
+	Action  string `json:"action,omitempty"`
+	Target  string `json:"target,omitempty"`
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+type SimulationOABucket struct {
+	BucketID    int
+	Allocations map[string]float64
+}
+
+type SimulationOA struct {
+	OAId        string
+	Capacity    *int
+	Ranking     *int
+	Allocations map[string]float64
+	PostalList  *string
+	OAGroup     *string
+	Buckets     []SimulationOABucket
+}
+
+type SimulationBucket struct {
+	BucketID   int
+	BucketName *string
+	MinDPD     int
+	MaxDPD     int
+}
+
+type SimulationConfig struct {
+	Name    string
+	OAs     []SimulationOA
+	Buckets []SimulationBucket
+}
+
+type Simulation struct {
+	SnapshotDate    time.Time
+	AssignBy        string
+	BalanceOverdue  bool
+	GroupByCustomer bool
+	Configurations  []SimulationConfig
+}
+
+// CapacityBreach is an OA the simulated run gave more accounts than its
+// capacity. Excess is how many accounts it holds beyond it.
+type CapacityBreach struct {
+	OAId         string `json:"oa_id"`
+	Capacity     int    `json:"capacity"`
+	AccountCount int    `json:"account_count"`
+	Excess       int    `json:"excess"`
+}
+
+// UnmetDemand is how many accounts of a bucket found every OA of their
+// oa_group full. OAGroup is empty for accounts any OA may take.
+type UnmetDemand struct {
+	BucketID     int    `json:"bucket_id"`
+	OAGroup      string `json:"oa_group"`
+	AccountCount int    `json:"account_count"`
+}
+
+type SimulationReport struct {
+	Name             string           `json:"name"`
+	BucketCounts     map[int]int      `json:"bucket_counts"`
+	UnassignedCounts map[string]int   `json:"unassigned_counts"`
+	OAs              []OAPreview      `json:"oas"`
+	Balance          []OABalance      `json:"balance"`
+	MaxImbalance     float64          `json:"max_imbalance"`
+	CapacityBreaches []CapacityBreach `json:"capacity_breaches"`
+	UnmetDemand      []UnmetDemand    `json:"unmet_demand"`
+}
+
+type SimulationResult struct {
+	SnapshotDate   string             `json:"snapshot_date"`
+	AssignBy       string             `json:"assign_by"`
+	AccountCount   int                `json:"account_count"`
+	Configurations []SimulationReport `json:"configurations"`
+}
@@ -0,0 +1,8 @@
+package model
+
+type AssignmentTransfer struct {
+	AccountID   string
+	ToOaID      *string
+	Reason      string
+	RequestedBy string
+}
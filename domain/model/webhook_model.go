@@ -0,0 +1,6 @@
+package model
+
+type OAWebhook struct {
+	WebhookURL    string
+	WebhookSecret string
+}
@@ -6,11 +6,11 @@ toolchain go1.23.11
 
 require (
 	github.com/gin-gonic/gin v1.10.1
+	github.com/google/uuid v1.4.0
 	github.com/xuri/excelize/v2 v2.9.1
 )
 
 require (
-	github.com/google/uuid v1.4.0 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
 	github.com/jackc/pgx/v5 v5.6.0 // indirect
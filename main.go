@@ -26,6 +26,8 @@ func main() {
 	r.PUT("/update-payment-status", controller.UpdatePaymentStatus)
 	r.POST("/upload-excel", controller.UploadExcel)
 	r.POST("/insert-transaction", controller.InsertTransaction)
+	r.POST("/contact-logs", controller.InsertContactLog)
+	r.GET("/accounts/:id/contact-logs", controller.GetContactLogs)
 
 	r.GET("/get-map-link", controller.GetMapsLinkHandler)
 	r.POST("/update-location", controller.UpdateLocationHandler)
@@ -33,6 +35,59 @@ func main() {
 	r.GET("/get-route", controller.GetRouteHandler)
 
 	r.PUT("/update-assignments-by-product-type", controller.UpdateAssignmentsByProductType)
+	r.PUT("/update-assignments-by-postal-code", controller.UpdateAssignmentsByPostalCode)
+	r.GET("/preview-assignments", controller.PreviewAssignments)
+	r.PUT("/update-assignments", controller.UpdateAssignments)
+	r.GET("/assignment-strategies", controller.GetAssignmentStrategies)
+	r.GET("/accounts/:id/assignment-history", controller.GetAssignmentHistory)
+	r.GET("/accounts/:id/assignment-explanation", controller.GetAssignmentExplanation)
+	r.GET("/assignments/unassigned", controller.GetUnassignedAccounts)
+	r.POST("/assignments/simulate", controller.SimulateAssignments)
+	r.POST("/account-snapshots", controller.CreateAccountSnapshot)
+	r.GET("/account-snapshots", controller.GetAccountSnapshots)
+
+	r.POST("/assignment-overrides", controller.CreateOverride)
+	r.GET("/assignment-overrides", controller.GetOverrides)
+	r.DELETE("/assignment-overrides/:override_id", controller.DeleteOverride)
+
+	r.GET("/assignment-transfers", controller.GetAssignmentTransfers)
+	r.POST("/assignment-transfers", controller.RequestAssignmentTransfer)
+	r.PUT("/assignment-transfers/:transfer_id/approve", controller.ApproveAssignmentTransfer)
+	r.PUT("/assignment-transfers/:transfer_id/reject", controller.RejectAssignmentTransfer)
+
+	r.GET("/routing-rules", controller.GetRoutingRules)
+	r.POST("/routing-rules", controller.CreateRoutingRule)
+	r.PUT("/routing-rules/:rule_id", controller.UpdateRoutingRule)
+	r.DELETE("/routing-rules/:rule_id", controller.DeleteRoutingRule)
+	r.POST("/assignment-rules/test", controller.TestAssignmentRule)
+
+	r.GET("/experiments", controller.GetExperiments)
+	r.POST("/experiments", controller.CreateExperiment)
+	r.PUT("/experiments/:experiment_id/stop", controller.StopExperiment)
+	r.GET("/experiments/:experiment_id/results", controller.GetExperimentResults)
+
+	r.GET("/buckets", controller.GetBuckets)
+	r.POST("/buckets", controller.CreateBucket)
+	r.PUT("/buckets/:bucket_id", controller.UpdateBucket)
+	r.DELETE("/buckets/:bucket_id", controller.DeleteBucket)
+
+	r.GET("/product-types", controller.GetProductTypes)
+	r.POST("/product-types", controller.CreateProductType)
+	r.PUT("/product-types/:product_type", controller.UpdateProductType)
+	r.GET("/oa/:oa_id/allocations", controller.GetOAAllocations)
+	r.PUT("/oa/:oa_id/allocations", controller.ReplaceOAAllocations)
+
+	r.GET("/oa/:oa_id/assignment-changes", controller.GetAssignmentChanges)
+	r.PUT("/oa/:oa_id/webhook", controller.UpdateOAWebhook)
+	r.GET("/oa/:oa_id/worklist.xlsx", controller.GetOAWorklist)
+	r.GET("/oa/worklists.zip", controller.GetAllOAWorklists)
+
+	r.GET("/job-runs", controller.GetJobRuns)
+
+	r.GET("/capacity-proposals", controller.GetCapacityProposals)
+	r.POST("/capacity-proposals/generate", controller.GenerateCapacityProposals)
+	r.PUT("/capacity-proposals/:proposal_id/approve", controller.ApproveCapacityProposal)
+	r.PUT("/capacity-proposals/:proposal_id/reject", controller.RejectCapacityProposal)
 
 	r.Run(":8080")
 }
@@ -42,4 +97,20 @@ func callRoutine() {
 	if err != nil {
 		log.Fatalf("failed to start batch routine: %v", err)
 	}
+	_, err = routine.StartCapacityRebalanceJob(context.Background())
+	if err != nil {
+		log.Fatalf("failed to start capacity rebalance routine: %v", err)
+	}
+	_, err = routine.StartAssignmentJob(context.Background())
+	if err != nil {
+		log.Fatalf("failed to start assignment routine: %v", err)
+	}
+	_, err = routine.StartSLARecallJob(context.Background())
+	if err != nil {
+		log.Fatalf("failed to start SLA recall routine: %v", err)
+	}
+	_, err = routine.StartWebhookRedeliveryJob(context.Background())
+	if err != nil {
+		log.Fatalf("failed to start webhook redelivery routine: %v", err)
+	}
 }
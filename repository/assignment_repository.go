@@ -2,14 +2,117 @@ package repository
 
 import (
 	"nhj-poc/domain/entity"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func DeleteAssignments(db *gorm.DB, productType string) error {
-	result := db.Where("assign_by = ?", productType).Delete(&entity.Assignments{})
+func ReleaseAssignments(db *gorm.DB, assignmentIDs []int, releasedAt time.Time) error {
+	if len(assignmentIDs) == 0 {
+		return nil
+	}
+	result := db.Model(&entity.Assignments{}).
+		Where("assignments_id IN ? AND released_at IS NULL", assignmentIDs).
+		Update("released_at", releasedAt)
 	if result.Error != nil {
 		return result.Error
 	}
 	return nil
 }
+
+// ReleaseAssignmentsWithReason releases the assignments and records why they
+// were taken away from their OA.
+func ReleaseAssignmentsWithReason(db *gorm.DB, assignmentIDs []int, releasedAt time.Time, reason string) error {
+	if len(assignmentIDs) == 0 {
+		return nil
+	}
+	return db.Model(&entity.Assignments{}).
+		Where("assignments_id IN ? AND released_at IS NULL", assignmentIDs).
+		Updates(map[string]interface{}{
+			"released_at":    releasedAt,
+			"release_reason": reason,
+		}).Error
+}
+
+// GetOpenAssignment returns the assignment the account currently holds.
+func GetOpenAssignment(db *gorm.DB, accountID string) (*entity.Assignments, error) {
+	var result entity.Assignments
+	if err := db.Model(&entity.Assignments{}).
+		Where("account_id = ? AND released_at IS NULL", accountID).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "assignments_id"}, Desc: true},
+		}}).
+		First(&result).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func CountOpenAssignmentsByOA(db *gorm.DB, oaID string) (int64, error) {
+	var count int64
+	if err := db.Model(&entity.Assignments{}).
+		Where("oa_id = ? AND released_at IS NULL", oaID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetAssignments returns the open assignments, the ones not released yet.
+func GetAssignments(db *gorm.DB, assignBy ...string) ([]entity.Assignments, error) {
+	var results []entity.Assignments
+	if err := db.Model(&entity.Assignments{}).
+		Where("assign_by IN ? AND released_at IS NULL", assignBy).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func GetAssignmentHistory(db *gorm.DB, accountID string, at *time.Time) ([]entity.Assignments, error) {
+	var results []entity.Assignments
+	query := db.Model(&entity.Assignments{}).
+		Where("account_id = ?", accountID)
+	if at != nil {
+		query = query.Where("assigned_at <= ? AND (released_at IS NULL OR released_at > ?)", *at, *at)
+	}
+	if err := query.
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "assigned_at"}, Desc: false},
+			{Column: clause.Column{Name: "assignments_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// ReplaceUnassignedAccounts swaps the unassigned backlog for the one of the
+// latest run.
+func ReplaceUnassignedAccounts(db *gorm.DB, unassigned []entity.UnassignedAccount) error {
+	if err := db.Where("1 = 1").Delete(&entity.UnassignedAccount{}).Error; err != nil {
+		return err
+	}
+	if len(unassigned) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(unassigned, 1000).Error
+}
+
+func GetUnassignedAccounts(db *gorm.DB, reason string) ([]entity.UnassignedAccount, error) {
+	var results []entity.UnassignedAccount
+	query := db.Model(&entity.UnassignedAccount{})
+	if reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+	if err := query.
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "reason"}, Desc: false},
+			{Column: clause.Column{Name: "account_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
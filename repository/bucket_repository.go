@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetAllBucket(db *gorm.DB) ([]entity.Bucket, error) {
+	var results []entity.Bucket
+	if err := db.Model(&entity.Bucket{}).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "min_dpd"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func GetBucketByBucketID(db *gorm.DB, bucketID int) (*entity.Bucket, error) {
+	var bucket entity.Bucket
+	if err := db.
+		Model(&entity.Bucket{}).
+		Where("bucket_id = ?", bucketID).
+		First(&bucket).Error; err != nil {
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+// BucketInUse reports whether an oa_bucket or oa_product_allocation row still
+// refers to the bucket.
+func BucketInUse(db *gorm.DB, bucketID int) (bool, error) {
+	var count int64
+	if err := db.
+		Model(&entity.OABucket{}).
+		Where("bucket_id = ?", bucketID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	if err := db.
+		Model(&entity.OAProductAllocation{}).
+		Where("bucket_id = ?", bucketID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetAssignmentChanges(db *gorm.DB, oaID string, since *time.Time, afterID int, limit int) ([]entity.AssignmentChange, error) {
+	var results []entity.AssignmentChange
+	query := db.Model(&entity.AssignmentChange{}).
+		Where("oa_id = ? AND change_id > ?", oaID, afterID)
+	if since != nil {
+		query = query.Where("created_at > ?", *since)
+	}
+	if err := query.
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "change_id"}, Desc: false},
+		}}).
+		Limit(limit).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// GetUndeliveredAssignmentChanges returns the changes of the OAs that were not
+// delivered and were created between from and to.
+func GetUndeliveredAssignmentChanges(db *gorm.DB, oaIDs []string, from time.Time, to time.Time) ([]entity.AssignmentChange, error) {
+	var results []entity.AssignmentChange
+	if len(oaIDs) == 0 {
+		return results, nil
+	}
+	if err := db.Model(&entity.AssignmentChange{}).
+		Where("oa_id IN ? AND delivered_at IS NULL AND created_at > ? AND created_at <= ?", oaIDs, from, to).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "change_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func MarkAssignmentChangesDelivered(db *gorm.DB, changeIDs []int, at time.Time) error {
+	if len(changeIDs) == 0 {
+		return nil
+	}
+	return db.Model(&entity.AssignmentChange{}).
+		Where("change_id IN ?", changeIDs).
+		Update("delivered_at", at).Error
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetContactLogs(db *gorm.DB, accountID string) ([]entity.ContactLog, error) {
+	var results []entity.ContactLog
+	if err := db.Model(&entity.ContactLog{}).
+		Where("account_id = ?", accountID).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "contacted_at"}, Desc: true},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// GetStaleAssignments returns the open assignments held since before cutoff
+// with no transaction on the account and no contact logged by the OA from
+// cutoff on. Assignments made by skipAssignBy are left out.
+func GetStaleAssignments(db *gorm.DB, cutoff time.Time, skipAssignBy string) ([]entity.Assignments, error) {
+	var results []entity.Assignments
+	if err := db.Model(&entity.Assignments{}).
+		Where("released_at IS NULL AND oa_id IS NOT NULL AND assigned_at <= ?", cutoff).
+		Where("assign_by IS NULL OR assign_by <> ?", skipAssignBy).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM transaction t
+			WHERE t.account_id = assignments.account_id AND t.transaction_date >= ?::date
+		)`, cutoff).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM contact_log c
+			WHERE c.account_id = assignments.account_id AND c.oa_id = assignments.oa_id AND c.contacted_at >= ?
+		)`, cutoff).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "assignments_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+
+	"gorm.io/gorm"
+)
+
+func GetAllCustomer(db *gorm.DB) ([]entity.Customer, error) {
+	var results []entity.Customer
+	if err := db.Model(&entity.Customer{}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
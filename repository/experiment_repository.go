@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetAllExperiment(db *gorm.DB) ([]entity.Experiment, error) {
+	var results []entity.Experiment
+	if err := db.Model(&entity.Experiment{}).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "experiment_id"}, Desc: true},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// GetActiveExperiment returns the running experiment, or nil when there is
+// none.
+func GetActiveExperiment(db *gorm.DB) (*entity.Experiment, error) {
+	var results []entity.Experiment
+	if err := db.Model(&entity.Experiment{}).
+		Where("active = ?", true).
+		Limit(1).
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return &results[0], nil
+}
+
+func GetExperimentByExperimentID(db *gorm.DB, experimentID int) (*entity.Experiment, error) {
+	var experiment entity.Experiment
+	if err := db.
+		Model(&entity.Experiment{}).
+		Where("experiment_id = ?", experimentID).
+		First(&experiment).Error; err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// GetExperimentOverdue sums, per arm, the distinct accounts the experiment
+// assigned and their overdue amount.
+func GetExperimentOverdue(db *gorm.DB, experimentID int) ([]entity.ExperimentArmAmount, error) {
+	var results []entity.ExperimentArmAmount
+	if err := db.
+		Table("(SELECT DISTINCT arm, account_id FROM assignments WHERE experiment_id = ? AND oa_id IS NOT NULL) e", experimentID).
+		Select("e.arm, COUNT(*) AS account_count, COALESCE(SUM(acc.overdue_amount), 0) AS amount").
+		Joins("JOIN account acc ON acc.account_id = e.account_id").
+		Group("e.arm").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetExperimentCollected sums, per arm, the transactions received while an
+// experiment assignment held the account.
+func GetExperimentCollected(db *gorm.DB, experimentID int) ([]entity.ExperimentArmAmount, error) {
+	var results []entity.ExperimentArmAmount
+	if err := db.
+		Table("transaction t").
+		Select("a.arm, COUNT(DISTINCT t.account_id) AS account_count, SUM(t.payment_amount) AS amount").
+		Joins("JOIN assignments a ON a.account_id = t.account_id AND t.transaction_date >= a.assigned_at::date AND (a.released_at IS NULL OR t.transaction_date < a.released_at::date)").
+		Where("a.experiment_id = ? AND a.oa_id IS NOT NULL", experimentID).
+		Group("a.arm").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetJobRuns(db *gorm.DB, jobName string, limit int) ([]entity.JobRun, error) {
+	var results []entity.JobRun
+	query := db.Model(&entity.JobRun{})
+	if jobName != "" {
+		query = query.Where("job_name = ?", jobName)
+	}
+	if err := query.
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "job_run_id"}, Desc: true},
+		}}).
+		Limit(limit).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// AdvisoryLock waits for the Postgres advisory lock of key. The lock is held
+// until the transaction of db ends.
+func AdvisoryLock(db *gorm.DB, key int64) error {
+	return db.Exec("SELECT pg_advisory_xact_lock(?)", key).Error
+}
+
+// JobRunning reports whether a run of jobName with the given status started
+// after since.
+func JobRunning(db *gorm.DB, jobName string, status string, since time.Time) (bool, error) {
+	var count int64
+	if err := db.
+		Model(&entity.JobRun{}).
+		Where("job_name = ? AND status = ? AND started_at > ?", jobName, status, since).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
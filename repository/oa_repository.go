@@ -18,3 +18,36 @@ func GetAllOA(db *gorm.DB) ([]entity.OA, error) {
 	}
 	return results, nil
 }
+
+func GetAllOABucket(db *gorm.DB) ([]entity.OABucket, error) {
+	var results []entity.OABucket
+	if err := db.Model(&entity.OABucket{}).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "bucket_id"}, Desc: false},
+			{Column: clause.Column{Name: "oa_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func UpdateOAWebhook(db *gorm.DB, oaID string, webhookURL string, webhookSecret string) error {
+	return db.Model(&entity.OA{}).
+		Where("oa_id = ?", oaID).
+		Updates(map[string]interface{}{
+			"webhook_url":    webhookURL,
+			"webhook_secret": webhookSecret,
+		}).Error
+}
+
+func OAIDExists(db *gorm.DB, oaID string) (bool, error) {
+	var count int64
+	if err := db.
+		Model(&entity.OA{}).
+		Where("oa_id = ?", oaID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetActiveOverrides returns the overrides without an expiry or expiring
+// after now.
+func GetActiveOverrides(db *gorm.DB, now time.Time) ([]entity.AssignmentOverride, error) {
+	var results []entity.AssignmentOverride
+	if err := db.Model(&entity.AssignmentOverride{}).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "override_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func GetAllOverrides(db *gorm.DB) ([]entity.AssignmentOverride, error) {
+	var results []entity.AssignmentOverride
+	if err := db.Model(&entity.AssignmentOverride{}).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "override_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func ActiveOverrideExists(db *gorm.DB, accountID string, now time.Time) (bool, error) {
+	var count int64
+	if err := db.
+		Model(&entity.AssignmentOverride{}).
+		Where("account_id = ? AND (expires_at IS NULL OR expires_at > ?)", accountID, now).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetActiveOverride returns the active override of the account, nil when it
+// has none.
+func GetActiveOverride(db *gorm.DB, accountID string, now time.Time) (*entity.AssignmentOverride, error) {
+	var results []entity.AssignmentOverride
+	if err := db.Model(&entity.AssignmentOverride{}).
+		Where("account_id = ? AND (expires_at IS NULL OR expires_at > ?)", accountID, now).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "override_id"}, Desc: true},
+		}}).
+		Limit(1).
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return &results[0], nil
+}
+
+// ExpireTransferOverrides ends at now the active overrides of the account
+// that a transfer or recall created.
+func ExpireTransferOverrides(db *gorm.DB, accountID string, now time.Time) error {
+	return db.Model(&entity.AssignmentOverride{}).
+		Where("account_id = ? AND transfer_id IS NOT NULL AND (expires_at IS NULL OR expires_at > ?)", accountID, now).
+		Update("expires_at", now).Error
+}
+
+func DeleteOverride(db *gorm.DB, overrideID int) (int64, error) {
+	result := db.Where("override_id = ?", overrideID).Delete(&entity.AssignmentOverride{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+
+	"gorm.io/gorm"
+)
+
+func GetAllPostalCodeCentroid(db *gorm.DB) ([]entity.PostalCodeCentroid, error) {
+	var results []entity.PostalCodeCentroid
+	if err := db.Model(&entity.PostalCodeCentroid{}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
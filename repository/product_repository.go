@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetAllProductType(db *gorm.DB) ([]entity.ProductType, error) {
+	var results []entity.ProductType
+	if err := db.Model(&entity.ProductType{}).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "product_type"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func GetActiveProductType(db *gorm.DB) ([]entity.ProductType, error) {
+	var results []entity.ProductType
+	if err := db.Model(&entity.ProductType{}).
+		Where("active = ?", true).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "product_type"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func ProductTypeExists(db *gorm.DB, productType string) (bool, error) {
+	var count int64
+	if err := db.
+		Model(&entity.ProductType{}).
+		Where("product_type = ?", productType).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func GetAllOAProductAllocation(db *gorm.DB) ([]entity.OAProductAllocation, error) {
+	var results []entity.OAProductAllocation
+	if err := db.Model(&entity.OAProductAllocation{}).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "oa_id"}, Desc: false},
+			{Column: clause.Column{Name: "allocation_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func GetOAProductAllocation(db *gorm.DB, oaID string) ([]entity.OAProductAllocation, error) {
+	var results []entity.OAProductAllocation
+	if err := db.Model(&entity.OAProductAllocation{}).
+		Where("oa_id = ?", oaID).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "allocation_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func DeleteOAProductAllocation(db *gorm.DB, oaID string) error {
+	return db.Where("oa_id = ?", oaID).Delete(&entity.OAProductAllocation{}).Error
+}
+
+// UpsertOAProductAllocation replaces the allocation of one product for the
+// bucket of the allocation, or the OA level one when bucket_id is null.
+func UpsertOAProductAllocation(db *gorm.DB, allocation entity.OAProductAllocation) error {
+	query := db.Where("oa_id = ? AND product_type = ?", allocation.OAId, allocation.ProductType)
+	if allocation.BucketID != nil && allocation.BucketID.Valid {
+		query = query.Where("bucket_id = ?", allocation.BucketID.Int32)
+	} else {
+		query = query.Where("bucket_id IS NULL")
+	}
+	if err := query.Delete(&entity.OAProductAllocation{}).Error; err != nil {
+		return err
+	}
+	return db.Create(&allocation).Error
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetCapacityProposals(db *gorm.DB, status string) ([]entity.CapacityProposal, error) {
+	var results []entity.CapacityProposal
+	query := db.Model(&entity.CapacityProposal{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "proposal_id"}, Desc: true},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func GetCapacityProposalByProposalID(db *gorm.DB, proposalID int) (*entity.CapacityProposal, error) {
+	var proposal entity.CapacityProposal
+	if err := db.
+		Model(&entity.CapacityProposal{}).
+		Where("proposal_id = ?", proposalID).
+		First(&proposal).Error; err != nil {
+		return nil, err
+	}
+	return &proposal, nil
+}
+
+func GetCapacityProposalsByPeriod(db *gorm.DB, period string) ([]entity.CapacityProposal, error) {
+	var results []entity.CapacityProposal
+	if err := db.Model(&entity.CapacityProposal{}).
+		Where("period = ?", period).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func DeleteCapacityProposals(db *gorm.DB, period string, status string) error {
+	return db.Where("period = ? AND status = ?", period, status).Delete(&entity.CapacityProposal{}).Error
+}
+
+// GetCollectedAmount sums the transactions received in [start, end) per OA
+// that held the account on the transaction date.
+func GetCollectedAmount(db *gorm.DB, start, end time.Time) ([]entity.OAProductAmount, error) {
+	var results []entity.OAProductAmount
+	if err := db.
+		Table("transaction t").
+		Select("a.oa_id, acc.product_type, SUM(t.payment_amount) AS amount").
+		Joins("JOIN assignments a ON a.account_id = t.account_id AND t.transaction_date >= a.assigned_at::date AND (a.released_at IS NULL OR t.transaction_date < a.released_at::date)").
+		Joins("JOIN account acc ON acc.account_id = t.account_id").
+		Where("a.oa_id IS NOT NULL AND t.transaction_date >= ? AND t.transaction_date < ?", start, end).
+		Group("a.oa_id, acc.product_type").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetHeldOverdueAmount sums the overdue amount of the accounts each OA held at
+// some point in [start, end).
+func GetHeldOverdueAmount(db *gorm.DB, start, end time.Time) ([]entity.OAProductAmount, error) {
+	var results []entity.OAProductAmount
+	if err := db.
+		Table("(SELECT DISTINCT oa_id, account_id FROM assignments WHERE oa_id IS NOT NULL AND assigned_at < ? AND (released_at IS NULL OR released_at >= ?)) h", end, start).
+		Select("h.oa_id, acc.product_type, SUM(acc.overdue_amount) AS amount").
+		Joins("JOIN account acc ON acc.account_id = h.account_id").
+		Group("h.oa_id, acc.product_type").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetAllRoutingRule(db *gorm.DB) ([]entity.RoutingRule, error) {
+	var results []entity.RoutingRule
+	if err := db.Model(&entity.RoutingRule{}).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "priority"}, Desc: false},
+			{Column: clause.Column{Name: "rule_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func GetActiveRoutingRule(db *gorm.DB) ([]entity.RoutingRule, error) {
+	var results []entity.RoutingRule
+	if err := db.Model(&entity.RoutingRule{}).
+		Where("active = ?", true).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "priority"}, Desc: false},
+			{Column: clause.Column{Name: "rule_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func GetRoutingRuleByRuleID(db *gorm.DB, ruleID int) (*entity.RoutingRule, error) {
+	var rule entity.RoutingRule
+	if err := db.
+		Model(&entity.RoutingRule{}).
+		Where("rule_id = ?", ruleID).
+		First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
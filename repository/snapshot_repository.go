@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetSnapshotSummaries(db *gorm.DB) ([]entity.SnapshotSummary, error) {
+	var results []entity.SnapshotSummary
+	if err := db.Model(&entity.AccountSnapshot{}).
+		Select("snapshot_date, COUNT(*) AS account_count").
+		Group("snapshot_date").
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "snapshot_date"}, Desc: true},
+		}}).
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func GetAccountSnapshot(db *gorm.DB, snapshotDate time.Time) ([]entity.AccountSnapshot, error) {
+	var results []entity.AccountSnapshot
+	if err := db.Model(&entity.AccountSnapshot{}).
+		Where("snapshot_date = ?", snapshotDate).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "outstanding_amount"}, Desc: true},
+			{Column: clause.Column{Name: "account_id"}, Desc: false},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func DeleteAccountSnapshot(db *gorm.DB, snapshotDate time.Time) error {
+	return db.Where("snapshot_date = ?", snapshotDate).Delete(&entity.AccountSnapshot{}).Error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReplaceAssignmentTraces swaps the traces for the ones of the latest run.
+func ReplaceAssignmentTraces(db *gorm.DB, traces []entity.AssignmentTrace) error {
+	if err := db.Where("1 = 1").Delete(&entity.AssignmentTrace{}).Error; err != nil {
+		return err
+	}
+	if len(traces) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(traces, 1000).Error
+}
+
+func GetAssignmentTrace(db *gorm.DB, accountID string) (*entity.AssignmentTrace, error) {
+	var result entity.AssignmentTrace
+	if err := db.Model(&entity.AssignmentTrace{}).
+		Where("account_id = ?", accountID).
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "trace_id"}, Desc: true},
+		}}).
+		First(&result).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
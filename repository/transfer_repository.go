@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetAssignmentTransfers(db *gorm.DB, status string) ([]entity.AssignmentTransfer, error) {
+	var results []entity.AssignmentTransfer
+	query := db.Model(&entity.AssignmentTransfer{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "transfer_id"}, Desc: true},
+		}}).
+		Find(&results).Error; err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func GetAssignmentTransferByTransferID(db *gorm.DB, transferID int) (*entity.AssignmentTransfer, error) {
+	var transfer entity.AssignmentTransfer
+	if err := db.
+		Model(&entity.AssignmentTransfer{}).
+		Where("transfer_id = ?", transferID).
+		First(&transfer).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func TransferExists(db *gorm.DB, accountID string, status string) (bool, error) {
+	var count int64
+	if err := db.
+		Model(&entity.AssignmentTransfer{}).
+		Where("account_id = ? AND status = ?", accountID, status).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"nhj-poc/domain/entity"
+
+	"gorm.io/gorm"
+)
+
+// GetWorklist returns the open assignments of the given OAs, or of every OA
+// when oaIDs is empty, most overdue first.
+func GetWorklist(db *gorm.DB, oaIDs []string) ([]entity.WorklistRow, error) {
+	var results []entity.WorklistRow
+	query := db.
+		Table("assignments a").
+		Select(`a.oa_id, acc.account_id, acc.customer_id, c.customer_name, o.occupation_name,
+			c.register_address, c.register_tambol, c.register_amphur, c.register_province, c.register_postal_code,
+			c.current_address, c.current_tambol, c.current_amphur, c.current_province, c.current_postal_code,
+			acc.product_type, acc.days_past_due, acc.outstanding_amount, acc.overdue_amount,
+			p.payment_title, p.due_date AS payment_due_date, p.payment_status_id, a.assigned_at, a.distance_km`).
+		Joins("JOIN account acc ON acc.account_id = a.account_id").
+		Joins("LEFT JOIN customer c ON c.customer_id = acc.customer_id").
+		Joins("LEFT JOIN occupation o ON o.occupation_id = c.occupation_id").
+		Joins("LEFT JOIN (SELECT DISTINCT ON (account_id) account_id, payment_title, due_date, payment_status_id FROM payment ORDER BY account_id, payment_id DESC) p ON p.account_id = acc.account_id").
+		Where("a.oa_id IS NOT NULL AND a.released_at IS NULL")
+	if len(oaIDs) > 0 {
+		query = query.Where("a.oa_id IN ?", oaIDs)
+	}
+	if err := query.
+		Order("a.oa_id, acc.days_past_due DESC NULLS LAST, acc.account_id").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
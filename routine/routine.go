@@ -2,8 +2,13 @@ package routine
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"nhj-poc/constant"
+	"nhj-poc/domain/model"
 	"nhj-poc/service"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/go-co-op/gocron"
@@ -30,3 +35,152 @@ func StartUpdatePaymentStatusJob(ctx context.Context) (*gocron.Scheduler, error)
 
 	return s, nil
 }
+
+func StartCapacityRebalanceJob(ctx context.Context) (*gocron.Scheduler, error) {
+	loc, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		return nil, err
+	}
+
+	s := gocron.NewScheduler(loc)
+
+	_, err = s.Every(1).Month(1).At("02:00").Do(func() {
+		log.Println("🔄 Monthly capacity rebalance job starting")
+		proposals, err := service.GenerateCapacityProposals(time.Now().In(loc).AddDate(0, 0, -1))
+		if err != nil {
+			log.Printf("❌ Monthly capacity rebalance job failed: %v", err)
+			return
+		}
+		log.Printf("✅ Monthly capacity rebalance job finished with %d proposals", len(proposals))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.StartAsync()
+
+	return s, nil
+}
+
+// StartAssignmentJob schedules the assignment run at ASSIGNMENT_JOB_AT
+// (HH:MM, Bangkok time) every day. The job is off when ASSIGNMENT_JOB_AT is
+// empty. ASSIGNMENT_JOB_ASSIGN_BY picks the strategy, ASSIGNMENT_JOB_STICKY
+// keeps current assignments where possible and ASSIGNMENT_JOB_GROUP_BY_CUSTOMER
+// assigns by customer.
+func StartAssignmentJob(ctx context.Context) (*gocron.Scheduler, error) {
+	at := os.Getenv("ASSIGNMENT_JOB_AT")
+	if at == "" {
+		log.Println("ASSIGNMENT_JOB_AT is not set, scheduled assignment run is off")
+		return nil, nil
+	}
+	assignBy := os.Getenv("ASSIGNMENT_JOB_ASSIGN_BY")
+	if assignBy == "" {
+		assignBy = constant.ASSIGN_BY_PRODUCT_TYPE
+	}
+	if _, err := service.GetAssignmentStrategy(assignBy); err != nil {
+		return nil, err
+	}
+	options := model.AssignmentOptions{
+		Sticky:          os.Getenv("ASSIGNMENT_JOB_STICKY") == "true",
+		GroupByCustomer: os.Getenv("ASSIGNMENT_JOB_GROUP_BY_CUSTOMER") == "true",
+	}
+
+	loc, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		return nil, err
+	}
+
+	s := gocron.NewScheduler(loc)
+
+	_, err = s.Every(1).Day().At(at).Do(func() {
+		log.Println("🔄 Daily assignment run job starting")
+		jobRun, err := service.RunScheduledAssignments(assignBy, options)
+		if err != nil {
+			log.Printf("❌ Daily assignment run job failed: %v", err)
+			return
+		}
+		log.Printf("✅ Daily assignment run job finished with status %s", jobRun.Status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.StartAsync()
+
+	return s, nil
+}
+
+// StartSLARecallJob releases, every day at SLA_RECALL_JOB_AT (HH:MM, Bangkok
+// time), the accounts an OA held for SLA_RECALL_DAYS with no transaction and
+// no logged contact. The job is off when SLA_RECALL_JOB_AT is empty.
+func StartSLARecallJob(ctx context.Context) (*gocron.Scheduler, error) {
+	at := os.Getenv("SLA_RECALL_JOB_AT")
+	if at == "" {
+		log.Println("SLA_RECALL_JOB_AT is not set, SLA recall is off")
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		return nil, err
+	}
+
+	s := gocron.NewScheduler(loc)
+
+	_, err = s.Every(1).Day().At(at).Do(func() {
+		log.Println("🔄 Daily SLA recall job starting")
+		jobRun, err := service.RunSLARecall()
+		if err != nil {
+			log.Printf("❌ Daily SLA recall job failed: %v", err)
+			return
+		}
+		log.Printf("✅ Daily SLA recall job finished, released %d accounts", jobRun.UnassignedCount.Int32)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.StartAsync()
+
+	return s, nil
+}
+
+// StartWebhookRedeliveryJob pushes again, every
+// WEBHOOK_REDELIVERY_EVERY_MINUTES, the assignment changes whose webhook
+// delivery failed. The job is off when the variable is empty.
+func StartWebhookRedeliveryJob(ctx context.Context) (*gocron.Scheduler, error) {
+	every := os.Getenv("WEBHOOK_REDELIVERY_EVERY_MINUTES")
+	if every == "" {
+		log.Println("WEBHOOK_REDELIVERY_EVERY_MINUTES is not set, webhook redelivery is off")
+		return nil, nil
+	}
+	minutes, err := strconv.Atoi(every)
+	if err != nil || minutes <= 0 {
+		return nil, fmt.Errorf("WEBHOOK_REDELIVERY_EVERY_MINUTES must be a positive number of minutes")
+	}
+
+	loc, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		return nil, err
+	}
+
+	s := gocron.NewScheduler(loc)
+
+	_, err = s.Every(minutes).Minutes().Do(func() {
+		jobRun, err := service.RedeliverAssignmentChanges()
+		if err != nil {
+			log.Printf("❌ Webhook redelivery job failed: %v", err)
+			return
+		}
+		if jobRun.AssignedCount != nil && jobRun.AssignedCount.Int32 > 0 {
+			log.Printf("✅ Webhook redelivery job delivered %d assignment changes", jobRun.AssignedCount.Int32)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.StartAsync()
+
+	return s, nil
+}
@@ -7,14 +7,24 @@ import (
 	"nhj-poc/constant"
 	"nhj-poc/database"
 	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
 	"nhj-poc/repository"
+	"nhj-poc/util"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type CapacityOA struct {
-	OAId        string
-	Capacity    int
-	CapacityC2C int
-	CapacityCRL int
+	OAId     string
+	Capacity int
+	// ProductCapacity is how many accounts of each product type the OA can
+	// still take in the bucket
+	ProductCapacity map[string]int
 }
 
 func ToNullString(s *string) *sql.NullString {
@@ -24,129 +34,994 @@ func ToNullString(s *string) *sql.NullString {
 	return &sql.NullString{Valid: false}
 }
 
-func UpdateAssignmentsByProductType() error {
+type assignmentData struct {
+	buckets          []entity.Bucket
+	accountsByBucket map[int][]entity.Account
+	nullDPDAccounts  []entity.Account
+	accountMap       map[string]entity.Account
+	customerMap      map[string]entity.Customer
+	centroidMap      map[string]entity.PostalCodeCentroid
+	oas              []entity.OA
+	oaBucketMap      map[string]map[int]entity.OABucket
+	// bucketAllocations holds the per bucket oa_product_allocation rows as
+	// oa_id -> bucket_id -> product_type -> percentage
+	bucketAllocations map[string]map[int]map[string]float64
+	productTypes      []string
+	// overrides holds the active override of each account
+	overrides map[string]entity.AssignmentOverride
+	rules     []routingRule
+	// experiment is the active champion/challenger experiment, if any
+	experiment *entity.Experiment
+	// openAssignments are the automatic assignments not released yet and
+	// currentAssignments the ones of them with an OA, keyed by account_id
+	openAssignments    []entity.Assignments
+	currentAssignments map[string]entity.Assignments
+}
+
+type assignmentPlan struct {
+	data         *assignmentData
+	assignments  []entity.Assignments
+	bucketCounts map[int]int
+	uncovered    []model.UncoveredAccount
+	unassigned   []model.UnassignedAccount
+	balance      []model.OABalance
+	// unmetDemand counts, per bucket and OA group, the accounts left over
+	// because the eligible OAs were full
+	unmetDemand []model.UnmetDemand
+	// traces explain the decision on every account the run looked at
+	traces []entity.AssignmentTrace
+}
+
+// UpdateAssignmentsByProductType splits every bucket by product percentage.
+// assignBy picks the allocation inside the split: round-robin for
+// ASSIGN_BY_PRODUCT_TYPE, or best ranking first for ASSIGN_BY_RANKING.
+func UpdateAssignmentsByProductType(assignBy string, options model.AssignmentOptions) (*model.AssignmentResult, error) {
+	if assignBy != constant.ASSIGN_BY_PRODUCT_TYPE && assignBy != constant.ASSIGN_BY_RANKING {
+		return nil, fmt.Errorf("assign_by %q is not supported", assignBy)
+	}
+	return runAssignments(assignBy, options)
+}
+
+// UpdateAssignments runs the strategy registered for assignBy.
+func UpdateAssignments(assignBy string, options model.AssignmentOptions) (*model.AssignmentResult, error) {
+	return runAssignments(assignBy, options)
+}
+
+func UpdateAssignmentsByPostalCode(options model.AssignmentOptions) (*model.AssignmentResult, error) {
+	return runAssignments(constant.ASSIGN_BY_POSTAL_CODE, options)
+}
+
+// GetUnassignedAccounts returns the accounts the latest run could not assign,
+// optionally only those with the given reason.
+func GetUnassignedAccounts(reason string) ([]entity.UnassignedAccount, error) {
+	unassigned, err := repository.GetUnassignedAccounts(database.DB, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unassigned accounts: %w", err)
+	}
+	return unassigned, nil
+}
+
+// PreviewAssignments runs the allocation for assignBy without writing anything
+// and compares the result with the assignments currently in the table.
+func PreviewAssignments(assignBy string, options model.AssignmentOptions) (*model.AssignmentPreview, error) {
+	plan, err := planAssignments(database.DB, assignBy, options)
+	if err != nil {
+		return nil, err
+	}
+
+	currentOA := make(map[string]string)
+	for accountID, assignment := range plan.data.currentAssignments {
+		currentOA[accountID] = assignment.OaID.String
+	}
+
+	var changes []model.AssignmentChange
+	newOA := make(map[string]string)
+	for _, assignment := range plan.assignments {
+		accountID := assignment.AccountID.String
+		oaID := assignment.OaID.String
+		newOA[accountID] = oaID
+		if currentOA[accountID] != oaID {
+			changes = append(changes, model.AssignmentChange{
+				AccountID:   accountID,
+				CurrentOaID: currentOA[accountID],
+				NewOaID:     oaID,
+			})
+		}
+	}
+	for accountID, assignment := range plan.data.currentAssignments {
+		if _, ok := newOA[accountID]; !ok && assignment.OaID.String != "" {
+			changes = append(changes, model.AssignmentChange{
+				AccountID:   accountID,
+				CurrentOaID: assignment.OaID.String,
+			})
+		}
+	}
+
+	preview := &model.AssignmentPreview{
+		AssignBy:          assignBy,
+		BucketCounts:      plan.bucketCounts,
+		UncoveredAccounts: plan.uncovered,
+		Unassigned:        plan.unassigned,
+		Balance:           plan.balance,
+		MaxImbalance:      getMaxImbalance(plan.balance),
+		Changes:           changes,
+		OAs:               getOALoad(plan),
+	}
+	return preview, nil
+}
+
+// getOALoad counts the accounts and the outstanding amount the plan gives
+// each OA against its capacity.
+func getOALoad(plan *assignmentPlan) []model.OAPreview {
+	oaPreviews := make(map[string]*model.OAPreview)
+	for _, oa := range plan.data.oas {
+		oaPreviews[oa.OAId] = &model.OAPreview{
+			OAId:     oa.OAId,
+			Capacity: int(oa.Capacity.Int16),
+		}
+	}
+	for _, assignment := range plan.assignments {
+		preview, ok := oaPreviews[assignment.OaID.String]
+		if !ok {
+			continue
+		}
+		preview.AccountCount++
+		if account, ok := plan.data.accountMap[assignment.AccountID.String]; ok && account.OutstandingAmount != nil {
+			preview.TotalOutstanding += int64(account.OutstandingAmount.Int32)
+		}
+	}
+
+	var load []model.OAPreview
+	for _, oa := range plan.data.oas {
+		oaPreview := oaPreviews[oa.OAId]
+		if oaPreview.Capacity > 0 {
+			oaPreview.Utilisation = float64(oaPreview.AccountCount) / float64(oaPreview.Capacity)
+		}
+		load = append(load, *oaPreview)
+	}
+	return load
+}
+
+// runAssignments applies a fresh plan in one transaction. Assignments that
+// stay with the same OA are left open; the others are released and the new
+// ones inserted under one run_id, so the table keeps the full history.
+func runAssignments(assignBy string, options model.AssignmentOptions) (*model.AssignmentResult, error) {
 	tx := database.DB.Begin()
 	if tx.Error != nil {
-		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
 	}
 	defer tx.Rollback()
 
-	// Delete Assignments product type
-	if err := repository.DeleteAssignments(tx, constant.ASSIGN_BY_PRODUCT_TYPE); err != nil {
-		return fmt.Errorf("failed to delete assignments: %w", err)
+	// Wait for an SLA recall or a transfer approval in progress so the plan
+	// starts from the assignments they leave
+	if err := repository.AdvisoryLock(tx, constant.ASSIGNMENTS_LOCK_KEY); err != nil {
+		return nil, fmt.Errorf("failed to lock assignments: %w", err)
 	}
 
-	//Get accounts data
-	accounts, err := repository.GetAllAccount(tx)
+	plan, err := planAssignments(tx, assignBy, options)
 	if err != nil {
-		return fmt.Errorf("failed to get all accounts: %w", err)
+		return nil, err
 	}
-	var accountsBucket1 []entity.Account
-	for _, account := range accounts {
-		if account.DaysPastDue.Int32 >= constant.BUCKET_1_MIN_DPD && account.DaysPastDue.Int32 <= constant.BUCKET_1_MAX_DPD {
-			accountsBucket1 = append(accountsBucket1, account)
+
+	now := time.Now()
+	runID := uuid.NewString()
+	planned := make(map[string]entity.Assignments)
+	for _, assignment := range plan.assignments {
+		planned[assignment.AccountID.String] = assignment
+	}
+
+	var releaseIDs []int
+	unchanged := make(map[string]bool)
+	for _, assignment := range plan.data.openAssignments {
+		accountID := assignment.AccountID.String
+		plannedAssignment, ok := planned[accountID]
+		if ok && isSameAssignment(plannedAssignment, assignment) && !unchanged[accountID] {
+			unchanged[accountID] = true
+			continue
 		}
+		releaseIDs = append(releaseIDs, assignment.AssignmentsID)
+	}
+	if err := repository.ReleaseAssignments(tx, releaseIDs, now); err != nil {
+		return nil, fmt.Errorf("failed to release assignments: %w", err)
 	}
-	countC2C := 0
-	countCRL := 0
-	for _, account := range accountsBucket1 {
-		if account.ProductType.String == constant.PRODUCT_TYPE_C2C {
-			countC2C++
-		} else if account.ProductType.String == constant.PRODUCT_TYPE_CRL {
-			countCRL++
+
+	var newAssignments []entity.Assignments
+	for _, assignment := range plan.assignments {
+		if unchanged[assignment.AccountID.String] {
+			continue
 		}
+		assignment.AssignedAt = &now
+		assignment.RunID = ToNullString(&runID)
+		newAssignments = append(newAssignments, assignment)
+	}
+	if err := saveAssignments(tx, newAssignments); err != nil {
+		return nil, err
+	}
+
+	var unassigned []entity.UnassignedAccount
+	unassignedCounts := make(map[string]int)
+	for _, account := range plan.unassigned {
+		unassigned = append(unassigned, entity.UnassignedAccount{
+			AccountID: account.AccountID,
+			Reason:    account.Reason,
+			AssignBy:  ToNullString(&assignBy),
+			RunID:     ToNullString(&runID),
+			CreatedAt: now,
+		})
+		unassignedCounts[account.Reason]++
+	}
+	if err := repository.ReplaceUnassignedAccounts(tx, unassigned); err != nil {
+		return nil, fmt.Errorf("failed to record unassigned accounts: %w", err)
+	}
+
+	for i := range plan.traces {
+		plan.traces[i].RunID = ToNullString(&runID)
+		plan.traces[i].CreatedAt = now
+	}
+	if err := repository.ReplaceAssignmentTraces(tx, plan.traces); err != nil {
+		return nil, fmt.Errorf("failed to record assignment traces: %w", err)
 	}
 
+	previousOA := make(map[string]string)
+	for _, assignment := range plan.data.openAssignments {
+		previousOA[assignment.AccountID.String] = assignment.OaID.String
+	}
+	nextOA := make(map[string]string)
+	for _, assignment := range plan.assignments {
+		nextOA[assignment.AccountID.String] = assignment.OaID.String
+	}
+	changes := getAssignmentChanges(previousOA, nextOA, &runID, now)
+	if err := saveAssignmentChanges(tx, changes); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	notifyAssignmentChanges(changes)
+	return &model.AssignmentResult{
+		RunID:             runID,
+		BucketCounts:      plan.bucketCounts,
+		UnassignedCounts:  unassignedCounts,
+		UncoveredAccounts: plan.uncovered,
+		Balance:           plan.balance,
+		MaxImbalance:      getMaxImbalance(plan.balance),
+	}, nil
+}
+
+// isSameAssignment reports whether a planned assignment matches an open one,
+// in which case the open row is kept as it is. An approved transfer pins the
+// account, so a manual plan on the OA of an open transfer row is the same
+// assignment.
+func isSameAssignment(planned entity.Assignments, open entity.Assignments) bool {
+	sameAssignBy := planned.AssignBy.String == open.AssignBy.String ||
+		(planned.AssignBy.String == constant.ASSIGN_BY_MANUAL && open.AssignBy.String == constant.ASSIGN_BY_TRANSFER)
+	return planned.OaID.String == open.OaID.String &&
+		getNullStringValue(planned.Queue) == getNullStringValue(open.Queue) &&
+		sameAssignBy &&
+		getNullInt32Value(planned.RuleID) == getNullInt32Value(open.RuleID) &&
+		getNullInt32Value(planned.ExperimentID) == getNullInt32Value(open.ExperimentID) &&
+		getNullStringValue(planned.Arm) == getNullStringValue(open.Arm)
+}
+
+// planAssignments allocates every bucket in memory with the strategy
+// registered for assignBy. Nothing is written.
+func planAssignments(db *gorm.DB, assignBy string, options model.AssignmentOptions) (*assignmentPlan, error) {
+	data, err := loadAssignmentData(db)
+	if err != nil {
+		return nil, err
+	}
+	return planAssignmentData(data, assignBy, options)
+}
+
+// planAssignmentData allocates the already loaded data. The simulator calls it
+// with data it built itself.
+func planAssignmentData(data *assignmentData, assignBy string, options model.AssignmentOptions) (*assignmentPlan, error) {
+	strategy, err := GetAssignmentStrategy(assignBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// The active experiment only applies to runs of its champion strategy
+	var experiment *entity.Experiment
+	var challenger AssignmentStrategy
+	if data.experiment != nil && data.experiment.ChampionAssignBy == assignBy {
+		experiment = data.experiment
+		challenger, err = GetAssignmentStrategy(experiment.ChallengerAssignBy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Remaining capacity is shared by every bucket
+	remainingCapacity := getRemainingCapacity(data.oas)
+
+	plan := &assignmentPlan{
+		data:         data,
+		bucketCounts: make(map[int]int),
+	}
+	// Pinned accounts go to their OA even when no bucket takes them
+	unbucketedPins := getUnbucketedPins(data)
+	plan.assignments = assignPinnedAccounts(unbucketedPins, data.overrides, make(map[string]CapacityOA), remainingCapacity)
+	plan.traces = tracePinnedAccounts(&sql.NullInt32{Valid: false}, unbucketedPins, data.overrides, nil, nil)
+	for _, account := range data.nullDPDAccounts {
+		if override, ok := data.overrides[account.AccountID]; ok &&
+			(override.OverrideType == constant.OVERRIDE_TYPE_PIN || override.OverrideType == constant.OVERRIDE_TYPE_LOCK) {
+			continue
+		}
+		plan.unassigned = append(plan.unassigned, model.UnassignedAccount{
+			AccountID: account.AccountID,
+			Reason:    constant.UNASSIGNED_NULL_DPD,
+		})
+		reason := constant.UNASSIGNED_NULL_DPD
+		trace := newAssignmentTrace(account, 0, assignBy, constant.TRACE_STEP_NULL_DPD)
+		trace.BucketID = &sql.NullInt32{Valid: false}
+		trace.Reason = ToNullString(&reason)
+		plan.traces = append(plan.traces, trace)
+	}
+	accountsByBucket := data.accountsByBucket
+	if options.GroupByCustomer {
+		accountsByBucket = groupAccountsByCustomer(data.accountsByBucket, data.buckets, data.overrides)
+	}
+	for _, bucket := range data.buckets {
+		bucketAccounts := accountsByBucket[bucket.BucketID]
+		if len(bucketAccounts) == 0 {
+			continue
+		}
+		bucketAccounts, pinnedAccounts := applyOverrides(bucketAccounts, data.overrides)
+		bucketOAs := getBucketOAs(bucket.BucketID, data.buckets[0].BucketID, data.oas, data.oaBucketMap, data.bucketAllocations)
+		capacityOA := getBucketCapacity(append(bucketAccounts, pinnedAccounts...), bucketOAs, remainingCapacity)
+
+		bucketTraces := tracePinnedAccounts(&sql.NullInt32{Int32: int32(bucket.BucketID), Valid: true}, pinnedAccounts, data.overrides, bucketOAs, capacityOA)
+		bucketAssignments := assignPinnedAccounts(pinnedAccounts, data.overrides, capacityOA, remainingCapacity)
+
+		// Routing rules run before the allocator; restricted groups go first
+		// so the general pool cannot use up their OAs
+		queuedAssignments, groupAccounts, firedRules := applyRoutingRules(bucketAccounts, data.customerMap, data.rules)
+		bucketAssignments = append(bucketAssignments, queuedAssignments...)
+		for _, assignment := range queuedAssignments {
+			trace := newAssignmentTrace(data.accountMap[assignment.AccountID.String], bucket.BucketID, constant.ASSIGN_BY_RULE, constant.TRACE_STEP_RULE)
+			trace.RuleID = assignment.RuleID
+			trace.Queue = assignment.Queue
+			bucketTraces = append(bucketTraces, trace)
+		}
+		for _, group := range getSortedGroups(groupAccounts) {
+			accounts := groupAccounts[group]
+			groupOAs := getGroupOAs(group, bucketOAs)
+			groupCapacity := copyCapacity(capacityOA)
+			groupAccountList := accounts
+
+			var groupAssignments []entity.Assignments
+			if options.Sticky {
+				groupAssignments, accounts = keepCurrentAssignments(accounts, data.currentAssignments, groupOAs, capacityOA)
+			}
+
+			result := assignWithExperiment(strategy, challenger, experiment, BucketInput{
+				Bucket:    bucket,
+				Accounts:  accounts,
+				OAs:       groupOAs,
+				Customers: data.customerMap,
+				Centroids: data.centroidMap,
+				Capacity:  capacityOA,
+				Options:   options,
+			})
+			// Kept sticky accounts were not split between the arms
+			if experiment != nil {
+				tagExperimentArm(result.Assignments, experiment)
+			}
+			kept := len(groupAssignments)
+			groupAssignments = append(groupAssignments, result.Assignments...)
+			for i, assignment := range groupAssignments {
+				if rule, ok := firedRules[assignment.AccountID.String]; ok {
+					groupAssignments[i].RuleID = util.IntToNullInt32(rule.RuleID)
+				}
+			}
+			bucketAssignments = append(bucketAssignments, groupAssignments...)
+			plan.uncovered = append(plan.uncovered, result.Uncovered...)
+			groupUnassigned := getUnassignedAccounts(accounts, groupOAs, result, data.productTypes)
+			plan.unassigned = append(plan.unassigned, groupUnassigned...)
+			if exhausted := countUnassigned(groupUnassigned, constant.UNASSIGNED_CAPACITY_EXHAUSTED); exhausted > 0 {
+				plan.unmetDemand = append(plan.unmetDemand, model.UnmetDemand{
+					BucketID:     bucket.BucketID,
+					OAGroup:      group,
+					AccountCount: exhausted,
+				})
+			}
+
+			unassignedReasons := make(map[string]string)
+			for _, account := range groupUnassigned {
+				unassignedReasons[account.AccountID] = account.Reason
+			}
+			bucketTraces = append(bucketTraces, traceGroup(bucket.BucketID, group, assignBy, groupAccountList, groupAssignments, kept, unassignedReasons, bucketOAs, groupOAs, groupCapacity, firedRules)...)
+		}
+
+		for oaID, capacity := range capacityOA {
+			remainingCapacity[oaID] = capacity.Capacity
+		}
+		bucketAssignments = expandCustomerGroups(bucketAssignments, bucketAccounts)
+		plan.traces = append(plan.traces, expandCustomerTraces(bucketTraces, bucketAccounts)...)
+		plan.bucketCounts[bucket.BucketID] = countAssigned(bucketAssignments)
+		plan.balance = append(plan.balance, getBalanceReport(bucket.BucketID, bucketOAs, bucketAssignments, data.accountMap, data.productTypes)...)
+		plan.assignments = append(plan.assignments, bucketAssignments...)
+	}
+	return plan, nil
+}
+
+func loadAssignmentData(db *gorm.DB) (*assignmentData, error) {
+	//Get bucket data
+	buckets, err := loadBuckets(db)
+	if err != nil {
+		return nil, err
+	}
+
+	//Get accounts data
+	accounts, err := repository.GetAllAccount(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all accounts: %w", err)
+	}
+	accountsByBucket, accountMap, nullDPDAccounts := groupAccountsByBucket(accounts, buckets)
+
 	//Get oa data
-	oas, err := repository.GetAllOA(tx)
+	oas, bucketAllocations, err := loadOAs(db)
+	if err != nil {
+		return nil, err
+	}
+	productTypes, err := loadProductTypes(db)
 	if err != nil {
-		return fmt.Errorf("failed to get all oa: %w", err)
+		return nil, err
+	}
+	oaBuckets, err := repository.GetAllOABucket(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all oa bucket: %w", err)
+	}
+	oaBucketMap := getOABucketMap(oaBuckets)
+
+	//Get customers data
+	customers, err := repository.GetAllCustomer(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all customers: %w", err)
+	}
+	customerMap := make(map[string]entity.Customer)
+	for _, customer := range customers {
+		customerMap[customer.CustomerID] = customer
+	}
+
+	//Get postal code centroids data
+	centroids, err := repository.GetAllPostalCodeCentroid(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all postal code centroids: %w", err)
+	}
+	centroidMap := make(map[string]entity.PostalCodeCentroid)
+	for _, centroid := range centroids {
+		centroidMap[centroid.PostalCode] = centroid
+	}
+
+	//Get current assignments data
+	openAssignments, err := repository.GetAssignments(db, getManagedAssignBy()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current assignments: %w", err)
+	}
+	currentAssignments := make(map[string]entity.Assignments)
+	for _, assignment := range openAssignments {
+		if assignment.AccountID.Valid && assignment.OaID.Valid {
+			currentAssignments[assignment.AccountID.String] = assignment
+		}
+	}
+
+	//Get overrides data
+	activeOverrides, err := repository.GetActiveOverrides(db, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active overrides: %w", err)
+	}
+	overrides := make(map[string]entity.AssignmentOverride)
+	for _, override := range activeOverrides {
+		overrides[override.AccountID] = override
+	}
+
+	//Get routing rules data
+	activeRules, err := repository.GetActiveRoutingRule(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active routing rules: %w", err)
+	}
+	rules, err := compileRoutingRules(activeRules)
+	if err != nil {
+		return nil, err
+	}
+
+	//Get active experiment data
+	experiment, err := repository.GetActiveExperiment(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active experiment: %w", err)
+	}
+
+	return &assignmentData{
+		buckets:            buckets,
+		accountsByBucket:   accountsByBucket,
+		nullDPDAccounts:    nullDPDAccounts,
+		accountMap:         accountMap,
+		customerMap:        customerMap,
+		centroidMap:        centroidMap,
+		oas:                oas,
+		oaBucketMap:        oaBucketMap,
+		bucketAllocations:  bucketAllocations,
+		productTypes:       productTypes,
+		overrides:          overrides,
+		rules:              rules,
+		experiment:         experiment,
+		openAssignments:    openAssignments,
+		currentAssignments: currentAssignments,
+	}, nil
+}
+
+// groupAccountsByBucket resolves the bucket of each account. Accounts without
+// a DPD are returned apart.
+func groupAccountsByBucket(accounts []entity.Account, buckets []entity.Bucket) (map[int][]entity.Account, map[string]entity.Account, []entity.Account) {
+	accountsByBucket := make(map[int][]entity.Account)
+	accountMap := make(map[string]entity.Account)
+	var nullDPDAccounts []entity.Account
+	for _, account := range accounts {
+		accountMap[account.AccountID] = account
+		if account.DaysPastDue == nil || !account.DaysPastDue.Valid {
+			nullDPDAccounts = append(nullDPDAccounts, account)
+		}
+		if bucketID, ok := resolveBucket(account.DaysPastDue, buckets); ok {
+			accountsByBucket[bucketID] = append(accountsByBucket[bucketID], account)
+		}
+	}
+	return accountsByBucket, accountMap, nullDPDAccounts
+}
+
+func getOABucketMap(oaBuckets []entity.OABucket) map[string]map[int]entity.OABucket {
+	oaBucketMap := make(map[string]map[int]entity.OABucket)
+	for _, oaBucket := range oaBuckets {
+		if _, ok := oaBucketMap[oaBucket.OAId]; !ok {
+			oaBucketMap[oaBucket.OAId] = make(map[int]entity.OABucket)
+		}
+		oaBucketMap[oaBucket.OAId][oaBucket.BucketID] = oaBucket
+	}
+	return oaBucketMap
+}
+
+func saveAssignments(tx *gorm.DB, assignments []entity.Assignments) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+	if err := tx.CreateInBatches(assignments, 1000).Error; err != nil {
+		return fmt.Errorf("failed to insert new assignment batch: %w", err)
+	}
+	return nil
+}
+
+func getRemainingCapacity(oas []entity.OA) map[string]int {
+	remainingCapacity := make(map[string]int)
+	for _, oa := range oas {
+		remainingCapacity[oa.OAId] = int(oa.Capacity.Int16)
+	}
+	return remainingCapacity
+}
+
+func countAssigned(assignments []entity.Assignments) int {
+	count := 0
+	for _, assignment := range assignments {
+		if assignment.OaID.Valid {
+			count++
+		}
+	}
+	return count
+}
+
+// getBucketOAs returns the OAs eligible for a bucket with their percentages for
+// that bucket. An OA takes the buckets it has an oa_bucket row or a per bucket
+// allocation for; an OA with neither keeps its OA level percentages and only
+// takes the first bucket, as it did before buckets were configurable.
+func getBucketOAs(bucketID int, firstBucketID int, oas []entity.OA, oaBucketMap map[string]map[int]entity.OABucket, bucketAllocations map[string]map[int]map[string]float64) []entity.OA {
+	var bucketOAs []entity.OA
+	for _, oa := range oas {
+		buckets, hasOABucket := oaBucketMap[oa.OAId]
+		allocationBuckets, hasAllocation := bucketAllocations[oa.OAId]
+		if !hasOABucket && !hasAllocation {
+			if bucketID == firstBucketID {
+				bucketOAs = append(bucketOAs, oa)
+			}
+			continue
+		}
+		oaBucket, inOABucket := buckets[bucketID]
+		allocations, inAllocation := allocationBuckets[bucketID]
+		if !inOABucket && !inAllocation {
+			continue
+		}
+
+		bucketAllocation := make(map[string]float64)
+		for productType, percentage := range oa.Allocations {
+			bucketAllocation[productType] = percentage
+		}
+		if inOABucket {
+			bucketAllocation[constant.PRODUCT_TYPE_C2C] = getNullFloat64Value(oaBucket.C2CPercentage)
+			bucketAllocation[constant.PRODUCT_TYPE_CRL] = getNullFloat64Value(oaBucket.CRLPercentage)
+		}
+		for productType, percentage := range allocations {
+			bucketAllocation[productType] = percentage
+		}
+		oa.Allocations = bucketAllocation
+		bucketOAs = append(bucketOAs, oa)
+	}
+	return bucketOAs
+}
+
+// getBucketCapacity splits each OA's product percentages over the accounts of
+// one bucket. OAs with no remaining capacity are left out.
+func getBucketCapacity(accounts []entity.Account, oas []entity.OA, remainingCapacity map[string]int) map[string]CapacityOA {
+	productCount := make(map[string]int)
+	for _, account := range accounts {
+		productCount[getNullStringValue(account.ProductType)] += getGroupSize(account)
 	}
 
-	var queueC2C []string
-	var queueCRL []string
 	capacityOA := make(map[string]CapacityOA)
 	for _, oa := range oas {
-		if oa.C2CPercentage.Float64 > 0 {
-			queueC2C = append(queueC2C, oa.OAId)
+		if remainingCapacity[oa.OAId] <= 0 {
+			continue
 		}
-		if oa.CRLPercentage.Float64 > 0 {
-			queueCRL = append(queueCRL, oa.OAId)
+		productCapacity := make(map[string]int)
+		for productType, percentage := range oa.Allocations {
+			productCapacity[productType] = int(math.Round(percentage * float64(productCount[productType])))
 		}
 		capacityOA[oa.OAId] = CapacityOA{
-			OAId:        oa.OAId,
-			Capacity:    int(oa.Capacity.Int16),
-			CapacityC2C: int(math.Round(oa.C2CPercentage.Float64 * float64(countC2C))),
-			CapacityCRL: int(math.Round(oa.CRLPercentage.Float64 * float64(countCRL))),
+			OAId:            oa.OAId,
+			Capacity:        remainingCapacity[oa.OAId],
+			ProductCapacity: productCapacity,
 		}
 	}
+	return capacityOA
+}
 
-	var assignments []entity.Assignments
-	productType := constant.ASSIGN_BY_PRODUCT_TYPE
-	for _, account := range accountsBucket1 {
-		var assignOaID string = ""
-		if account.ProductType.String == constant.PRODUCT_TYPE_C2C {
-			if len(queueC2C) > 0 {
-				assignOaID = queueC2C[0]
-				queueC2C = queueC2C[1:]
-				capacityOA[assignOaID] = CapacityOA{
-					OAId:        assignOaID,
-					Capacity:    capacityOA[assignOaID].Capacity - 1,
-					CapacityC2C: capacityOA[assignOaID].CapacityC2C - 1,
-					CapacityCRL: capacityOA[assignOaID].CapacityCRL,
-				}
-				if capacityOA[assignOaID].Capacity > 0 {
-					if capacityOA[assignOaID].CapacityC2C > 0 {
-						queueC2C = append(queueC2C, assignOaID)
-					}
-				} else {
-					var newQueueCRL []string
-					for _, oa := range queueCRL {
-						if oa != assignOaID {
-							newQueueCRL = append(newQueueCRL, oa)
-						}
-					}
-					queueCRL = newQueueCRL
-				}
+// getUnassignedAccounts finds the accounts the strategy left without an OA
+// and works out why.
+func getUnassignedAccounts(accounts []entity.Account, oas []entity.OA, result BucketResult, productTypes []string) []model.UnassignedAccount {
+	assigned := make(map[string]bool)
+	for _, assignment := range result.Assignments {
+		assigned[assignment.AccountID.String] = true
+	}
+	uncovered := make(map[string]bool)
+	for _, account := range result.Uncovered {
+		uncovered[account.AccountID] = true
+	}
+
+	var unassigned []model.UnassignedAccount
+	for _, account := range accounts {
+		if assigned[account.AccountID] {
+			continue
+		}
+		reason := constant.UNASSIGNED_CAPACITY_EXHAUSTED
+		if !slices.Contains(productTypes, getNullStringValue(account.ProductType)) {
+			reason = constant.UNASSIGNED_UNKNOWN_PRODUCT_TYPE
+		} else if uncovered[account.AccountID] || !hasEligibleOA(account.ProductType.String, oas) {
+			reason = constant.UNASSIGNED_NO_ELIGIBLE_OA
+		}
+		unassigned = append(unassigned, model.UnassignedAccount{
+			AccountID: account.AccountID,
+			Reason:    reason,
+		})
+		for _, member := range account.Members {
+			unassigned = append(unassigned, model.UnassignedAccount{
+				AccountID: member.AccountID,
+				Reason:    reason,
+			})
+		}
+	}
+	return unassigned
+}
+
+func countUnassigned(unassigned []model.UnassignedAccount, reason string) int {
+	count := 0
+	for _, account := range unassigned {
+		if account.Reason == reason {
+			count++
+		}
+	}
+	return count
+}
+
+func hasEligibleOA(productType string, oas []entity.OA) bool {
+	for _, oa := range oas {
+		if getProductPercentage(oa, productType) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getBalanceReport compares, per product, the outstanding and overdue share
+// each OA got in the bucket with the share its percentage entitles it to.
+func getBalanceReport(bucketID int, oas []entity.OA, assignments []entity.Assignments, accountMap map[string]entity.Account, productTypes []string) []model.OABalance {
+	var report []model.OABalance
+	for _, productType := range productTypes {
+		totalPercentage := 0.0
+		for _, oa := range oas {
+			totalPercentage += getProductPercentage(oa, productType)
+		}
+		if totalPercentage <= 0 {
+			continue
+		}
+
+		outstandingHeld := make(map[string]int64)
+		overdueHeld := make(map[string]int64)
+		var totalOutstanding, totalOverdue int64
+		for _, assignment := range assignments {
+			account, ok := accountMap[assignment.AccountID.String]
+			if !ok || !assignment.OaID.Valid || account.ProductType.String != productType {
+				continue
 			}
-		} else if account.ProductType.String == constant.PRODUCT_TYPE_CRL {
-			if len(queueCRL) > 0 {
-				assignOaID = queueCRL[0]
-				queueCRL = queueCRL[1:]
-				capacityOA[assignOaID] = CapacityOA{
-					OAId:        assignOaID,
-					Capacity:    capacityOA[assignOaID].Capacity - 1,
-					CapacityC2C: capacityOA[assignOaID].CapacityC2C,
-					CapacityCRL: capacityOA[assignOaID].CapacityCRL - 1,
-				}
-				if capacityOA[assignOaID].Capacity > 0 {
-					if capacityOA[assignOaID].CapacityCRL > 0 {
-						queueCRL = append(queueCRL, assignOaID)
-					}
-				} else {
-					var newQueueC2C []string
-					for _, oa := range queueC2C {
-						if oa != assignOaID {
-							newQueueC2C = append(newQueueC2C, oa)
-						}
-					}
-					queueC2C = newQueueC2C
-				}
+			outstandingHeld[assignment.OaID.String] += getAmount(account.OutstandingAmount)
+			overdueHeld[assignment.OaID.String] += getAmount(account.OverdueAmount)
+			totalOutstanding += getAmount(account.OutstandingAmount)
+			totalOverdue += getAmount(account.OverdueAmount)
+		}
+		if totalOutstanding == 0 && totalOverdue == 0 {
+			continue
+		}
+
+		for _, oa := range oas {
+			percentage := getProductPercentage(oa, productType)
+			if percentage <= 0 {
+				continue
+			}
+			balance := model.OABalance{
+				BucketID:         bucketID,
+				ProductType:      productType,
+				OAId:             oa.OAId,
+				TargetShare:      percentage / totalPercentage,
+				TotalOutstanding: outstandingHeld[oa.OAId],
+				TotalOverdue:     overdueHeld[oa.OAId],
+			}
+			// A total of zero has no share to miss, so it adds no imbalance
+			if totalOutstanding > 0 {
+				balance.OutstandingShare = float64(balance.TotalOutstanding) / float64(totalOutstanding)
+				balance.Imbalance = math.Abs(balance.OutstandingShare - balance.TargetShare)
+			}
+			if totalOverdue > 0 {
+				balance.OverdueShare = float64(balance.TotalOverdue) / float64(totalOverdue)
+				balance.Imbalance = math.Max(balance.Imbalance, math.Abs(balance.OverdueShare-balance.TargetShare))
 			}
+			report = append(report, balance)
+		}
+	}
+	return report
+}
+
+func getMaxImbalance(report []model.OABalance) float64 {
+	maxImbalance := 0.0
+	for _, balance := range report {
+		maxImbalance = math.Max(maxImbalance, balance.Imbalance)
+	}
+	return maxImbalance
+}
+
+func getProductPercentage(oa entity.OA, productType string) float64 {
+	return oa.Allocations[productType]
+}
+
+func getNullStringValue(value *sql.NullString) string {
+	if value == nil || !value.Valid {
+		return ""
+	}
+	return value.String
+}
+
+func getNullFloat64Value(value *sql.NullFloat64) float64 {
+	if value == nil || !value.Valid {
+		return 0
+	}
+	return value.Float64
+}
+
+func getNullInt32Value(value *sql.NullInt32) int32 {
+	if value == nil || !value.Valid {
+		return 0
+	}
+	return value.Int32
+}
+
+func getAmount(amount *sql.NullInt32) int64 {
+	if amount == nil || !amount.Valid {
+		return 0
+	}
+	return int64(amount.Int32)
+}
+
+// applyOverrides drops locked accounts and splits off the pinned ones, which
+// skip the allocator.
+func applyOverrides(accounts []entity.Account, overrides map[string]entity.AssignmentOverride) ([]entity.Account, []entity.Account) {
+	var free []entity.Account
+	var pinned []entity.Account
+	for _, account := range accounts {
+		override, ok := overrides[account.AccountID]
+		if !ok {
+			free = append(free, account)
+			continue
+		}
+		switch override.OverrideType {
+		case constant.OVERRIDE_TYPE_PIN:
+			pinned = append(pinned, account)
+		case constant.OVERRIDE_TYPE_EXCLUDE:
+			account.ExcludedOaID = override.OaID.String
+			free = append(free, account)
+		}
+	}
+	return free, pinned
+}
+
+// assignPinnedAccounts gives pinned accounts to their OA and counts them
+// against its capacity even when the OA is already full or not eligible for
+// the bucket.
+func assignPinnedAccounts(accounts []entity.Account, overrides map[string]entity.AssignmentOverride, capacityOA map[string]CapacityOA, remainingCapacity map[string]int) []entity.Assignments {
+	var assignments []entity.Assignments
+	manual := constant.ASSIGN_BY_MANUAL
+	for _, account := range accounts {
+		oaID := overrides[account.AccountID].OaID.String
+		if !bookPinnedAccount(capacityOA, oaID, account) {
+			remainingCapacity[oaID]--
 		}
 		assignments = append(assignments, entity.Assignments{
 			AccountID: ToNullString(&account.AccountID),
-			OaID:      ToNullString(&assignOaID),
-			AssignBy:  ToNullString(&productType),
+			OaID:      ToNullString(&oaID),
+			AssignBy:  ToNullString(&manual),
 		})
 	}
+	return assignments
+}
 
-	if err := tx.CreateInBatches(assignments, 1000).Error; err != nil {
-		return fmt.Errorf("failed to insert new assignment batch: %w", err)
+// getUnbucketedPins returns the pinned accounts that fall in no bucket,
+// because they have no DPD or a DPD outside every range.
+func getUnbucketedPins(data *assignmentData) []entity.Account {
+	var accounts []entity.Account
+	for accountID, override := range data.overrides {
+		if override.OverrideType != constant.OVERRIDE_TYPE_PIN {
+			continue
+		}
+		account, ok := data.accountMap[accountID]
+		if !ok {
+			continue
+		}
+		if _, ok := resolveBucket(account.DaysPastDue, data.buckets); !ok {
+			accounts = append(accounts, account)
+		}
 	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].AccountID < accounts[j].AccountID
+	})
+	return accounts
+}
 
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+// bookPinnedAccount counts a pinned account against the OA's capacity in the
+// bucket, full or not, and reports whether the OA takes part in the bucket.
+func bookPinnedAccount(capacityOA map[string]CapacityOA, oaID string, account entity.Account) bool {
+	capacity, ok := capacityOA[oaID]
+	if !ok {
+		return false
 	}
-	return nil
+	capacity.Capacity--
+	if _, ok := capacity.ProductCapacity[account.ProductType.String]; ok {
+		capacity.ProductCapacity[account.ProductType.String]--
+	}
+	capacityOA[oaID] = capacity
+	return true
+}
+
+// applyRoutingRules evaluates the routing rules against each account. Accounts
+// sent to a queue get their queue assignment straight away; the others are
+// grouped by the oa_group they are restricted to, "" meaning any OA.
+func applyRoutingRules(accounts []entity.Account, customerMap map[string]entity.Customer, rules []routingRule) ([]entity.Assignments, map[string][]entity.Account, map[string]entity.RoutingRule) {
+	var queued []entity.Assignments
+	groupAccounts := make(map[string][]entity.Account)
+	firedRules := make(map[string]entity.RoutingRule)
+	ruleAssignBy := constant.ASSIGN_BY_RULE
+	for _, account := range accounts {
+		rule := matchRoutingRule(account, customerMap[account.CustomerID], rules)
+		if rule == nil {
+			groupAccounts[""] = append(groupAccounts[""], account)
+			continue
+		}
+		firedRules[account.AccountID] = *rule
+		switch rule.Action {
+		case constant.RULE_ACTION_QUEUE:
+			queued = append(queued, entity.Assignments{
+				AccountID: ToNullString(&account.AccountID),
+				OaID:      ToNullString(nil),
+				AssignBy:  ToNullString(&ruleAssignBy),
+				Queue:     ToNullString(&rule.Target),
+				RuleID:    util.IntToNullInt32(rule.RuleID),
+			})
+		case constant.RULE_ACTION_OA_GROUP:
+			groupAccounts[rule.Target] = append(groupAccounts[rule.Target], account)
+		}
+	}
+	return queued, groupAccounts, firedRules
+}
+
+// getSortedGroups orders the oa_groups with the unrestricted group last.
+func getSortedGroups(groupAccounts map[string][]entity.Account) []string {
+	var groups []string
+	for group := range groupAccounts {
+		if group != "" {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	if _, ok := groupAccounts[""]; ok {
+		groups = append(groups, "")
+	}
+	return groups
+}
+
+func getGroupOAs(group string, oas []entity.OA) []entity.OA {
+	if group == "" {
+		return oas
+	}
+	var groupOAs []entity.OA
+	for _, oa := range oas {
+		if oa.OAGroup != nil && oa.OAGroup.Valid && strings.EqualFold(oa.OAGroup.String, group) {
+			groupOAs = append(groupOAs, oa)
+		}
+	}
+	return groupOAs
+}
+
+// keepCurrentAssignments keeps each account with its current OA when that OA
+// is still one of oas and still has capacity for the product.
+// It returns the kept assignments and the accounts left for the allocator.
+func keepCurrentAssignments(accounts []entity.Account, currentAssignments map[string]entity.Assignments, oas []entity.OA, capacityOA map[string]CapacityOA) ([]entity.Assignments, []entity.Account) {
+	eligible := make(map[string]bool)
+	for _, oa := range oas {
+		eligible[oa.OAId] = true
+	}
+
+	var kept []entity.Assignments
+	var released []entity.Account
+	for _, account := range accounts {
+		current, ok := currentAssignments[account.AccountID]
+		if ok && eligible[current.OaID.String] &&
+			current.AssignBy.String != constant.ASSIGN_BY_MANUAL &&
+			takeCapacity(capacityOA, current.OaID.String, account) {
+			kept = append(kept, entity.Assignments{
+				AccountID: ToNullString(&account.AccountID),
+				OaID:      ToNullString(&current.OaID.String),
+				AssignBy:  ToNullString(&current.AssignBy.String),
+			})
+			continue
+		}
+		released = append(released, account)
+	}
+	return kept, released
+}
+
+// takeCapacity books the account, and the accounts it stands for, against the
+// OA and reports whether the OA had room for them.
+func takeCapacity(capacityOA map[string]CapacityOA, oaID string, account entity.Account) bool {
+	capacity, ok := capacityOA[oaID]
+	if !ok || !hasProductCapacity(capacity, account) {
+		return false
+	}
+	size := getGroupSize(account)
+	capacity.ProductCapacity[account.ProductType.String] -= size
+	capacity.Capacity -= size
+	capacityOA[oaID] = capacity
+	return true
+}
+
+// GetAssignmentHistory lists every assignment of the account, or only the one
+// open at the given time when at is set.
+func GetAssignmentHistory(accountID string, at *time.Time) ([]entity.Assignments, error) {
+	exists, err := repository.AccountIDExists(database.DB, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("account_id not found")
+	}
+
+	history, err := repository.GetAssignmentHistory(database.DB, accountID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment history: %w", err)
+	}
+	return history, nil
 }
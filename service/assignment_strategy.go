@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"nhj-poc/constant"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"sort"
+)
+
+// AssignmentStrategy allocates the accounts of one bucket to OAs. The run
+// handles the transaction, the delete and the batch insert; a strategy only
+// decides which OA gets which account.
+type AssignmentStrategy interface {
+	// AssignBy is the assign_by value the strategy is registered under and
+	// writes on its assignments
+	AssignBy() string
+	Description() string
+	AssignBucket(input BucketInput) BucketResult
+}
+
+type BucketInput struct {
+	Bucket    entity.Bucket
+	Accounts  []entity.Account
+	OAs       []entity.OA
+	Customers map[string]entity.Customer
+	Centroids map[string]entity.PostalCodeCentroid
+	// Capacity is shared with the run; take from it with takeCapacity
+	Capacity map[string]CapacityOA
+	Options  model.AssignmentOptions
+}
+
+type BucketResult struct {
+	Assignments []entity.Assignments
+	Uncovered   []model.UncoveredAccount
+}
+
+var assignmentStrategies = make(map[string]AssignmentStrategy)
+
+func RegisterAssignmentStrategy(strategy AssignmentStrategy) {
+	if _, ok := assignmentStrategies[strategy.AssignBy()]; ok {
+		panic(fmt.Sprintf("assignment strategy %q registered twice", strategy.AssignBy()))
+	}
+	assignmentStrategies[strategy.AssignBy()] = strategy
+}
+
+func GetAssignmentStrategy(assignBy string) (AssignmentStrategy, error) {
+	strategy, ok := assignmentStrategies[assignBy]
+	if !ok {
+		return nil, fmt.Errorf("assign_by %q is not supported", assignBy)
+	}
+	return strategy, nil
+}
+
+func GetAssignmentStrategies() []model.AssignmentStrategy {
+	var strategies []model.AssignmentStrategy
+	for _, assignBy := range getRegisteredAssignBy() {
+		strategies = append(strategies, model.AssignmentStrategy{
+			AssignBy:    assignBy,
+			Description: assignmentStrategies[assignBy].Description(),
+		})
+	}
+	return strategies
+}
+
+func getRegisteredAssignBy() []string {
+	var assignBy []string
+	for key := range assignmentStrategies {
+		assignBy = append(assignBy, key)
+	}
+	sort.Strings(assignBy)
+	return assignBy
+}
+
+// getManagedAssignBy lists the assign_by values an assignment run manages:
+// every strategy plus the manual pins, rule queues and approved transfers. A
+// run replaces all of them so an account is never held twice.
+func getManagedAssignBy() []string {
+	return append(getRegisteredAssignBy(), constant.ASSIGN_BY_MANUAL, constant.ASSIGN_BY_RULE, constant.ASSIGN_BY_TRANSFER)
+}
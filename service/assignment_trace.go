@@ -0,0 +1,233 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetAssignmentExplanation returns the open assignment of the account and the
+// trace the latest run recorded for it. The trace is flagged stale when the
+// open assignment was not made by that run.
+func GetAssignmentExplanation(accountID string) (*entity.Assignments, *entity.AssignmentTrace, error) {
+	exists, err := repository.AccountIDExists(database.DB, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, fmt.Errorf("account_id not found")
+	}
+
+	trace, err := repository.GetAssignmentTrace(database.DB, accountID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, fmt.Errorf("no assignment run has traced account %s yet", accountID)
+		}
+		return nil, nil, fmt.Errorf("failed to get assignment trace: %w", err)
+	}
+
+	now := time.Now()
+	open, err := repository.GetAssignmentHistory(database.DB, accountID, &now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get assignment history: %w", err)
+	}
+	if len(open) == 0 {
+		trace.Stale = getNullStringValue(trace.OaID) != ""
+		return nil, trace, nil
+	}
+	assignment := &open[len(open)-1]
+	trace.Stale = getNullStringValue(trace.OaID) != getNullStringValue(assignment.OaID) ||
+		(assignment.AssignedAt != nil && assignment.AssignedAt.After(trace.CreatedAt))
+	return assignment, trace, nil
+}
+
+func newAssignmentTrace(account entity.Account, bucketID int, assignBy string, step string) entity.AssignmentTrace {
+	return entity.AssignmentTrace{
+		AccountID: account.AccountID,
+		BucketID:  &sql.NullInt32{Int32: int32(bucketID), Valid: true},
+		AssignBy:  assignBy,
+		Step:      step,
+		RuleID:    &sql.NullInt32{Valid: false},
+		Queue:     &sql.NullString{Valid: false},
+		OAGroup:   &sql.NullString{Valid: false},
+		Position:  &sql.NullInt32{Valid: false},
+		OaID:      &sql.NullString{Valid: false},
+		Reason:    &sql.NullString{Valid: false},
+
+		LeadAccountID: &sql.NullString{Valid: false},
+	}
+}
+
+// traceGroup replays the allocation of one oa_group from the capacity it
+// started with, so each account gets the candidates and their capacity as they
+// stood when it was allocated. The strategies book capacity in the order they
+// return assignments, sticky ones first; accounts left over are traced
+// against the capacity at the end.
+func traceGroup(bucketID int, group string, assignBy string, accounts []entity.Account, assignments []entity.Assignments, kept int, unassignedReasons map[string]string, bucketOAs []entity.OA, groupOAs []entity.OA, startCapacity map[string]CapacityOA, firedRules map[string]entity.RoutingRule) []entity.AssignmentTrace {
+	accountMap := make(map[string]entity.Account)
+	for _, account := range accounts {
+		accountMap[account.AccountID] = account
+	}
+	inGroup := make(map[string]bool)
+	for _, oa := range groupOAs {
+		inGroup[oa.OAId] = true
+	}
+	capacityOA := copyCapacity(startCapacity)
+
+	var traces []entity.AssignmentTrace
+	assigned := make(map[string]bool)
+	for i, assignment := range assignments {
+		account, ok := accountMap[assignment.AccountID.String]
+		if !ok {
+			continue
+		}
+		step := constant.TRACE_STEP_STRATEGY
+		if i < kept {
+			step = constant.TRACE_STEP_STICKY
+		}
+		trace := newAssignmentTrace(account, bucketID, assignment.AssignBy.String, step)
+		trace.Position = &sql.NullInt32{Int32: int32(i + 1), Valid: true}
+		trace.OaID = assignment.OaID
+		trace.Candidates = getTraceCandidates(account, assignment.OaID.String, bucketOAs, inGroup, capacityOA)
+		traces = append(traces, trace)
+
+		takeCapacity(capacityOA, assignment.OaID.String, account)
+		assigned[account.AccountID] = true
+	}
+
+	for _, account := range accounts {
+		if assigned[account.AccountID] {
+			continue
+		}
+		trace := newAssignmentTrace(account, bucketID, assignBy, constant.TRACE_STEP_STRATEGY)
+		reason := unassignedReasons[account.AccountID]
+		trace.Reason = ToNullString(&reason)
+		trace.Candidates = getTraceCandidates(account, "", bucketOAs, inGroup, capacityOA)
+		traces = append(traces, trace)
+	}
+
+	for i, trace := range traces {
+		if group != "" {
+			traces[i].OAGroup = ToNullString(&group)
+		}
+		if rule, ok := firedRules[trace.AccountID]; ok {
+			traces[i].RuleID = &sql.NullInt32{Int32: int32(rule.RuleID), Valid: true}
+		}
+	}
+	return traces
+}
+
+// tracePinnedAccounts traces the accounts pinned by an override. Every other
+// OA of the bucket is listed as a candidate the pin ruled out, with the
+// capacity as it stood when the pinned accounts were booked.
+func tracePinnedAccounts(bucketID *sql.NullInt32, accounts []entity.Account, overrides map[string]entity.AssignmentOverride, bucketOAs []entity.OA, startCapacity map[string]CapacityOA) []entity.AssignmentTrace {
+	inGroup := make(map[string]bool)
+	for _, oa := range bucketOAs {
+		inGroup[oa.OAId] = true
+	}
+	capacityOA := copyCapacity(startCapacity)
+
+	var traces []entity.AssignmentTrace
+	for _, account := range accounts {
+		oaID := overrides[account.AccountID].OaID.String
+		trace := newAssignmentTrace(account, 0, constant.ASSIGN_BY_MANUAL, constant.TRACE_STEP_OVERRIDE)
+		trace.BucketID = bucketID
+		trace.OaID = ToNullString(&oaID)
+
+		candidates := getTraceCandidates(account, oaID, bucketOAs, inGroup, capacityOA)
+		selected := false
+		for i := range candidates {
+			if candidates[i].Selected {
+				selected = true
+			} else {
+				candidates[i].RejectReason = constant.REJECT_PINNED_ELSEWHERE
+			}
+		}
+		if !selected {
+			candidates = append(candidates, entity.TraceCandidate{OaID: oaID, Selected: true})
+		}
+		trace.Candidates = candidates
+		traces = append(traces, trace)
+
+		bookPinnedAccount(capacityOA, oaID, account)
+	}
+	return traces
+}
+
+// getTraceCandidates lists every OA of the bucket with why it was not chosen.
+func getTraceCandidates(account entity.Account, oaID string, bucketOAs []entity.OA, inGroup map[string]bool, capacityOA map[string]CapacityOA) []entity.TraceCandidate {
+	productType := account.ProductType.String
+	var candidates []entity.TraceCandidate
+	for _, oa := range bucketOAs {
+		capacity, ok := capacityOA[oa.OAId]
+		candidate := entity.TraceCandidate{
+			OaID:              oa.OAId,
+			Percentage:        getProductPercentage(oa, productType),
+			RemainingCapacity: capacity.Capacity,
+			ProductCapacity:   capacity.ProductCapacity[productType],
+		}
+		switch {
+		case oa.OAId == oaID:
+			candidate.Selected = true
+		case !inGroup[oa.OAId]:
+			candidate.RejectReason = constant.REJECT_NOT_IN_OA_GROUP
+		case oa.OAId == account.ExcludedOaID:
+			candidate.RejectReason = constant.REJECT_EXCLUDED_BY_OVERRIDE
+		case candidate.Percentage <= 0:
+			candidate.RejectReason = constant.REJECT_NO_PRODUCT_ALLOCATION
+		case !ok || capacity.Capacity < getGroupSize(account):
+			candidate.RejectReason = constant.REJECT_CAPACITY_EXHAUSTED
+		case candidate.ProductCapacity <= 0:
+			candidate.RejectReason = constant.REJECT_PRODUCT_CAPACITY_EXHAUSTED
+		default:
+			candidate.RejectReason = constant.REJECT_NOT_PREFERRED_BY_STRATEGY
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+func copyCapacity(capacityOA map[string]CapacityOA) map[string]CapacityOA {
+	copied := make(map[string]CapacityOA, len(capacityOA))
+	for oaID, capacity := range capacityOA {
+		productCapacity := make(map[string]int, len(capacity.ProductCapacity))
+		for productType, count := range capacity.ProductCapacity {
+			productCapacity[productType] = count
+		}
+		capacity.ProductCapacity = productCapacity
+		copied[oaID] = capacity
+	}
+	return copied
+}
+
+// expandCustomerTraces gives every member of a grouped account the trace of
+// its lead.
+func expandCustomerTraces(traces []entity.AssignmentTrace, accounts []entity.Account) []entity.AssignmentTrace {
+	accountMap := make(map[string]entity.Account)
+	for _, account := range accounts {
+		if len(account.Members) > 0 {
+			accountMap[account.AccountID] = account
+		}
+	}
+	if len(accountMap) == 0 {
+		return traces
+	}
+
+	expanded := make([]entity.AssignmentTrace, 0, len(traces))
+	for _, trace := range traces {
+		expanded = append(expanded, trace)
+		for _, member := range accountMap[trace.AccountID].Members {
+			memberTrace := trace
+			memberTrace.AccountID = member.AccountID
+			memberTrace.LeadAccountID = ToNullString(&trace.AccountID)
+			expanded = append(expanded, memberTrace)
+		}
+	}
+	return expanded
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"math"
+	"nhj-poc/constant"
+	"nhj-poc/domain/entity"
+)
+
+type balanceStrategy struct{}
+
+func init() {
+	RegisterAssignmentStrategy(balanceStrategy{})
+}
+
+func (balanceStrategy) AssignBy() string {
+	return constant.ASSIGN_BY_BALANCE
+}
+
+func (balanceStrategy) Description() string {
+	return "Balances total outstanding (and optionally overdue) per OA in proportion to each OA's product percentage"
+}
+
+func (balanceStrategy) AssignBucket(input BucketInput) BucketResult {
+	return BucketResult{
+		Assignments: assignBucketByBalance(input.Accounts, input.OAs, input.Capacity, input.Options.BalanceOverdue),
+	}
+}
+
+// assignBucketByBalance gives each account, largest outstanding first, to the
+// OA whose amount held is lowest relative to its product percentage once the
+// account is added. With balanceOverdue the overdue amount is weighed in
+// equally with the outstanding amount.
+func assignBucketByBalance(accounts []entity.Account, oas []entity.OA, capacityOA map[string]CapacityOA, balanceOverdue bool) []entity.Assignments {
+	totalOutstanding := make(map[string]float64)
+	totalOverdue := make(map[string]float64)
+	for _, account := range accounts {
+		totalOutstanding[account.ProductType.String] += float64(getOutstandingAmount(account))
+		totalOverdue[account.ProductType.String] += float64(getOverdueAmount(account))
+	}
+
+	outstandingHeld := make(map[string]float64)
+	overdueHeld := make(map[string]float64)
+	var assignments []entity.Assignments
+	balance := constant.ASSIGN_BY_BALANCE
+	for _, account := range accounts {
+		productType := account.ProductType.String
+		outstanding := float64(getOutstandingAmount(account))
+		overdue := float64(getOverdueAmount(account))
+
+		var assignOaID string = ""
+		bestLoad := math.Inf(1)
+		for _, oa := range oas {
+			share := getProductPercentage(oa, productType)
+			capacity, ok := capacityOA[oa.OAId]
+			if share <= 0 || !ok || !hasProductCapacity(capacity, account) {
+				continue
+			}
+			key := oa.OAId + "|" + productType
+			load := getLoad(outstandingHeld[key]+outstanding, share, totalOutstanding[productType])
+			if balanceOverdue {
+				load = (load + getLoad(overdueHeld[key]+overdue, share, totalOverdue[productType])) / 2
+			}
+			if load < bestLoad {
+				assignOaID = oa.OAId
+				bestLoad = load
+			}
+		}
+
+		if assignOaID == "" || !takeCapacity(capacityOA, assignOaID, account) {
+			continue
+		}
+		key := assignOaID + "|" + productType
+		outstandingHeld[key] += outstanding
+		overdueHeld[key] += overdue
+		assignments = append(assignments, entity.Assignments{
+			AccountID: ToNullString(&account.AccountID),
+			OaID:      ToNullString(&assignOaID),
+			AssignBy:  ToNullString(&balance),
+		})
+	}
+	return assignments
+}
+
+// getLoad is the part of its target amount an OA holds.
+func getLoad(held float64, share float64, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return held / (share * total)
+}
+
+// hasProductCapacity reports whether the OA has room for the account and
+// every account it stands for. Only the total capacity has to fit the whole
+// group; the product share just has to have room left. An OA the account is
+// excluded from never has room for it.
+func hasProductCapacity(capacity CapacityOA, account entity.Account) bool {
+	if account.ExcludedOaID != "" && capacity.OAId == account.ExcludedOaID {
+		return false
+	}
+	return capacity.Capacity >= getGroupSize(account) && capacity.ProductCapacity[account.ProductType.String] > 0
+}
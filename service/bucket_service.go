@@ -0,0 +1,192 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"nhj-poc/repository"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// loadBuckets returns the configured buckets. A run cannot resolve any
+// account without them, so an empty bucket table is an error.
+func loadBuckets(db *gorm.DB) ([]entity.Bucket, error) {
+	buckets, err := repository.GetAllBucket(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all bucket: %w", err)
+	}
+	if len(buckets) == 0 {
+		return nil, fmt.Errorf("no bucket configured")
+	}
+	return buckets, nil
+}
+
+func GetBuckets() ([]entity.Bucket, error) {
+	buckets, err := repository.GetAllBucket(database.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all bucket: %w", err)
+	}
+	return buckets, nil
+}
+
+func CreateBucket(bModel model.Bucket) (*entity.Bucket, error) {
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	buckets, err := repository.GetAllBucket(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all bucket: %w", err)
+	}
+
+	bucket := entity.Bucket{
+		BucketName: ToNullString(bModel.BucketName),
+		MinDPD:     bModel.MinDPD,
+		MaxDPD:     bModel.MaxDPD,
+	}
+	if err := validateBuckets(append(buckets, bucket)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Create(&bucket).Error; err != nil {
+		return nil, fmt.Errorf("failed to insert bucket: %w", err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &bucket, nil
+}
+
+func UpdateBucket(bucketID int, bModel model.Bucket) (*entity.Bucket, error) {
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	buckets, err := repository.GetAllBucket(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all bucket: %w", err)
+	}
+
+	var bucket *entity.Bucket
+	for i := range buckets {
+		if buckets[i].BucketID == bucketID {
+			bucket = &buckets[i]
+		}
+	}
+	if bucket == nil {
+		return nil, fmt.Errorf("bucket_id %d not found", bucketID)
+	}
+	bucket.BucketName = ToNullString(bModel.BucketName)
+	bucket.MinDPD = bModel.MinDPD
+	bucket.MaxDPD = bModel.MaxDPD
+	if err := validateBuckets(buckets); err != nil {
+		return nil, err
+	}
+
+	if err := tx.
+		Model(&entity.Bucket{}).
+		Where("bucket_id = ?", bucketID).
+		Updates(map[string]interface{}{
+			"bucket_name": bucket.BucketName,
+			"min_dpd":     bucket.MinDPD,
+			"max_dpd":     bucket.MaxDPD,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update bucket %d: %w", bucketID, err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return bucket, nil
+}
+
+func DeleteBucket(bucketID int) error {
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	buckets, err := repository.GetAllBucket(tx)
+	if err != nil {
+		return fmt.Errorf("failed to get all bucket: %w", err)
+	}
+
+	var remaining []entity.Bucket
+	for _, bucket := range buckets {
+		if bucket.BucketID != bucketID {
+			remaining = append(remaining, bucket)
+		}
+	}
+	if len(remaining) == len(buckets) {
+		return fmt.Errorf("bucket_id %d not found", bucketID)
+	}
+	if err := validateBuckets(remaining); err != nil {
+		return err
+	}
+
+	inUse, err := repository.BucketInUse(tx, bucketID)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return fmt.Errorf("bucket_id %d is still used by an OA allocation", bucketID)
+	}
+
+	if err := tx.Where("bucket_id = ?", bucketID).Delete(&entity.Bucket{}).Error; err != nil {
+		return fmt.Errorf("failed to delete bucket %d: %w", bucketID, err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// validateBuckets checks that the bucket ranges, ordered by min_dpd, follow
+// each other without a gap or an overlap.
+func validateBuckets(buckets []entity.Bucket) error {
+	sorted := make([]entity.Bucket, len(buckets))
+	copy(sorted, buckets)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].MinDPD < sorted[j].MinDPD
+	})
+
+	for i, bucket := range sorted {
+		if bucket.MinDPD < 0 {
+			return fmt.Errorf("min_dpd %d must not be negative", bucket.MinDPD)
+		}
+		if bucket.MinDPD > bucket.MaxDPD {
+			return fmt.Errorf("min_dpd %d is greater than max_dpd %d", bucket.MinDPD, bucket.MaxDPD)
+		}
+		if i == 0 {
+			continue
+		}
+		previous := sorted[i-1]
+		if bucket.MinDPD <= previous.MaxDPD {
+			return fmt.Errorf("dpd range %d-%d overlaps %d-%d", bucket.MinDPD, bucket.MaxDPD, previous.MinDPD, previous.MaxDPD)
+		}
+		if bucket.MinDPD != previous.MaxDPD+1 {
+			return fmt.Errorf("dpd %d-%d is not covered by any bucket", previous.MaxDPD+1, bucket.MinDPD-1)
+		}
+	}
+	return nil
+}
+
+func resolveBucket(daysPastDue *sql.NullInt32, buckets []entity.Bucket) (int, bool) {
+	if daysPastDue == nil || !daysPastDue.Valid {
+		return 0, false
+	}
+	for _, bucket := range buckets {
+		if int(daysPastDue.Int32) >= bucket.MinDPD && int(daysPastDue.Int32) <= bucket.MaxDPD {
+			return bucket.BucketID, true
+		}
+	}
+	return 0, false
+}
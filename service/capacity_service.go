@@ -0,0 +1,312 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/repository"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const proposalPeriodLayout = "2006-01"
+
+// The rebalancing formula moves each OA's percentage towards its share of the
+// pool's collection rate:
+//
+//	performance share = rate / sum of rates * sum of current percentages
+//	proposed          = current + weight * (performance share - current)
+//
+// Percentages are fractions from 0 to 1, so the move is capped at max step
+// of that scale (0.10 is ten points) and the result is scaled back so the pool
+// keeps the same total.
+var (
+	rebalanceWeight  = loadEnvFloat("REBALANCE_WEIGHT", 0.5)
+	rebalanceMaxStep = loadEnvFloat("REBALANCE_MAX_STEP", 0.10)
+)
+
+func loadEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(loadEnvVar(key), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func loadEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(loadEnvVar(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func GetCapacityProposals(status string) ([]entity.CapacityProposal, error) {
+	proposals, err := repository.GetCapacityProposals(database.DB, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capacity proposals: %w", err)
+	}
+	return proposals, nil
+}
+
+// GenerateCapacityProposals computes the collection rate of every OA for the
+// month starting at period and replaces the pending proposals of that month.
+// Proposals are made per OA, product type and bucket, comparing the OAs that
+// work the bucket; an OA without bucket settings gets one OA level proposal.
+// The ones already decided for the month are left alone.
+func GenerateCapacityProposals(period time.Time) ([]entity.CapacityProposal, error) {
+	start := time.Date(period.Year(), period.Month(), 1, 0, 0, 0, 0, period.Location())
+	end := start.AddDate(0, 1, 0)
+	periodName := start.Format(proposalPeriodLayout)
+
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	buckets, err := loadBuckets(tx)
+	if err != nil {
+		return nil, err
+	}
+	oas, bucketAllocations, err := loadOAs(tx)
+	if err != nil {
+		return nil, err
+	}
+	oaBuckets, err := repository.GetAllOABucket(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all oa bucket: %w", err)
+	}
+	oaBucketMap := getOABucketMap(oaBuckets)
+	productTypes, err := loadProductTypes(tx)
+	if err != nil {
+		return nil, err
+	}
+	collected, err := repository.GetCollectedAmount(tx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collected amount: %w", err)
+	}
+	held, err := repository.GetHeldOverdueAmount(tx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get held overdue amount: %w", err)
+	}
+	existing, err := repository.GetCapacityProposalsByPeriod(tx, periodName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capacity proposals of %s: %w", periodName, err)
+	}
+
+	decided := make(map[string]bool)
+	for _, proposal := range existing {
+		if proposal.Status != constant.PROPOSAL_STATUS_PENDING {
+			decided[getProposalKey(proposal.OaID, proposal.ProductType, proposal.BucketID)] = true
+		}
+	}
+
+	rates := getCollectionRates(collected, held, productTypes)
+
+	now := time.Now()
+	var proposals []entity.CapacityProposal
+	for _, bucket := range buckets {
+		bucketOAs := getBucketOAs(bucket.BucketID, buckets[0].BucketID, oas, oaBucketMap, bucketAllocations)
+		for _, productType := range productTypes {
+			proposed := proposePercentages(bucketOAs, productType, rates[productType])
+			for _, oa := range bucketOAs {
+				bucketID := getProposalBucketID(oa.OAId, bucket.BucketID, oaBucketMap, bucketAllocations)
+				rate, hasRate := rates[productType][oa.OAId]
+				if decided[getProposalKey(oa.OAId, productType, bucketID)] || !hasRate {
+					continue
+				}
+				current, hasCurrent := oa.Allocations[productType]
+				currentPercentage := &sql.NullFloat64{Float64: current, Valid: hasCurrent}
+				proposals = append(proposals, entity.CapacityProposal{
+					OaID:               oa.OAId,
+					Period:             periodName,
+					ProductType:        productType,
+					BucketID:           bucketID,
+					CollectionRate:     toNullFloat64(rate, hasRate),
+					CurrentPercentage:  currentPercentage,
+					ProposedPercentage: getProposedPercentage(oa.OAId, currentPercentage, proposed),
+					Status:             constant.PROPOSAL_STATUS_PENDING,
+					CreatedAt:          now,
+				})
+			}
+		}
+	}
+
+	if err := repository.DeleteCapacityProposals(tx, periodName, constant.PROPOSAL_STATUS_PENDING); err != nil {
+		return nil, fmt.Errorf("failed to delete pending capacity proposals of %s: %w", periodName, err)
+	}
+	if len(proposals) > 0 {
+		if err := tx.Create(&proposals).Error; err != nil {
+			return nil, fmt.Errorf("failed to insert capacity proposals: %w", err)
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return proposals, nil
+}
+
+// ApproveCapacityProposal writes the proposed percentage back as the
+// allocation of the product for the proposal's bucket, or at OA level. A
+// bucket allocation takes precedence over the oa_bucket percentages, so the
+// approved value is the one the next run uses.
+func ApproveCapacityProposal(proposalID int, decidedBy string) (*entity.CapacityProposal, error) {
+	return decideCapacityProposal(proposalID, decidedBy, constant.PROPOSAL_STATUS_APPROVED)
+}
+
+func RejectCapacityProposal(proposalID int, decidedBy string) (*entity.CapacityProposal, error) {
+	return decideCapacityProposal(proposalID, decidedBy, constant.PROPOSAL_STATUS_REJECTED)
+}
+
+func decideCapacityProposal(proposalID int, decidedBy string, status string) (*entity.CapacityProposal, error) {
+	if decidedBy == "" {
+		return nil, fmt.Errorf("decided_by is required")
+	}
+
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	proposal, err := repository.GetCapacityProposalByProposalID(tx, proposalID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("proposal_id %d not found", proposalID)
+		}
+		return nil, err
+	}
+	if proposal.Status != constant.PROPOSAL_STATUS_PENDING {
+		return nil, fmt.Errorf("proposal_id %d is already %s", proposalID, proposal.Status)
+	}
+
+	if status == constant.PROPOSAL_STATUS_APPROVED {
+		if proposal.ProposedPercentage != nil && proposal.ProposedPercentage.Valid {
+			if err := repository.UpsertOAProductAllocation(tx, entity.OAProductAllocation{
+				OAId:        proposal.OaID,
+				ProductType: proposal.ProductType,
+				BucketID:    proposal.BucketID,
+				Percentage:  proposal.ProposedPercentage.Float64,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to update %s allocation for OA %s: %w", proposal.ProductType, proposal.OaID, err)
+			}
+		}
+	}
+
+	now := time.Now()
+	proposal.Status = status
+	proposal.DecidedAt = &now
+	proposal.DecidedBy = ToNullString(&decidedBy)
+	if err := tx.
+		Model(&entity.CapacityProposal{}).
+		Where("proposal_id = ?", proposalID).
+		Updates(map[string]interface{}{
+			"status":     proposal.Status,
+			"decided_at": proposal.DecidedAt,
+			"decided_by": proposal.DecidedBy,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update capacity proposal %d: %w", proposalID, err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return proposal, nil
+}
+
+// getProposalBucketID returns the bucket a proposal for the OA is made for:
+// the bucket itself when the OA has bucket settings, null otherwise.
+func getProposalBucketID(oaID string, bucketID int, oaBucketMap map[string]map[int]entity.OABucket, bucketAllocations map[string]map[int]map[string]float64) *sql.NullInt32 {
+	_, hasOABucket := oaBucketMap[oaID]
+	_, hasAllocation := bucketAllocations[oaID]
+	if !hasOABucket && !hasAllocation {
+		return &sql.NullInt32{Valid: false}
+	}
+	return &sql.NullInt32{Int32: int32(bucketID), Valid: true}
+}
+
+func getProposalKey(oaID string, productType string, bucketID *sql.NullInt32) string {
+	if bucketID == nil || !bucketID.Valid {
+		return oaID + "|" + productType + "|"
+	}
+	return oaID + "|" + productType + "|" + strconv.Itoa(int(bucketID.Int32))
+}
+
+// getCollectionRates divides what each OA collected by the overdue amount it
+// held, per product type. OAs that held nothing of a product get no rate.
+func getCollectionRates(collected []entity.OAProductAmount, held []entity.OAProductAmount, productTypes []string) map[string]map[string]float64 {
+	collectedMap := make(map[string]int64)
+	for _, row := range collected {
+		collectedMap[row.ProductType+"|"+row.OaID] = row.Amount
+	}
+
+	rates := make(map[string]map[string]float64)
+	for _, productType := range productTypes {
+		rates[productType] = make(map[string]float64)
+	}
+	for _, row := range held {
+		if _, ok := rates[row.ProductType]; !ok || row.Amount <= 0 {
+			continue
+		}
+		rates[row.ProductType][row.OaID] = float64(collectedMap[row.ProductType+"|"+row.OaID]) / float64(row.Amount)
+	}
+	return rates
+}
+
+// proposePercentages applies the rebalancing formula to the OAs that take the
+// product and have a collection rate for it.
+func proposePercentages(oas []entity.OA, productType string, rates map[string]float64) map[string]float64 {
+	var pool []entity.OA
+	totalPercentage := 0.0
+	totalRate := 0.0
+	for _, oa := range oas {
+		rate, ok := rates[oa.OAId]
+		if !ok || getProductPercentage(oa, productType) <= 0 {
+			continue
+		}
+		pool = append(pool, oa)
+		totalPercentage += getProductPercentage(oa, productType)
+		totalRate += rate
+	}
+
+	proposed := make(map[string]float64)
+	if len(pool) < 2 || totalRate <= 0 {
+		return proposed
+	}
+
+	totalProposed := 0.0
+	for _, oa := range pool {
+		current := getProductPercentage(oa, productType)
+		performanceShare := rates[oa.OAId] / totalRate * totalPercentage
+		step := rebalanceWeight * (performanceShare - current)
+		step = math.Max(-rebalanceMaxStep, math.Min(rebalanceMaxStep, step))
+		proposed[oa.OAId] = math.Max(0, current+step)
+		totalProposed += proposed[oa.OAId]
+	}
+	for oaID, percentage := range proposed {
+		proposed[oaID] = math.Round(percentage*totalPercentage/totalProposed*100) / 100
+	}
+	return proposed
+}
+
+func getProposedPercentage(oaID string, current *sql.NullFloat64, proposed map[string]float64) *sql.NullFloat64 {
+	if percentage, ok := proposed[oaID]; ok {
+		return &sql.NullFloat64{Float64: percentage, Valid: true}
+	}
+	if current == nil {
+		return &sql.NullFloat64{Valid: false}
+	}
+	return current
+}
+
+func toNullFloat64(value float64, valid bool) *sql.NullFloat64 {
+	if !valid {
+		return &sql.NullFloat64{Valid: false}
+	}
+	return &sql.NullFloat64{Float64: math.Round(value*10000) / 10000, Valid: true}
+}
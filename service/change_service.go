@@ -0,0 +1,286 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"nhj-poc/repository"
+	"nhj-poc/util"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook deliveries are retried with a doubling delay. Receivers verify
+// X-Signature, the hex HMAC-SHA256 of "<X-Timestamp>.<body>" keyed with the
+// OA's webhook_secret; an OA without a secret gets no webhook. Changes that
+// still fail are picked up again by the redelivery job once they are older
+// than webhookRedeliverAfter, for up to webhookRedeliverFor.
+var (
+	webhookMaxAttempts    = loadEnvInt("WEBHOOK_MAX_ATTEMPTS", 3)
+	webhookRetryDelay     = time.Duration(loadEnvInt("WEBHOOK_RETRY_DELAY_SECONDS", 2)) * time.Second
+	webhookClient         = &http.Client{Timeout: 10 * time.Second}
+	webhookRedeliverAfter = 10 * time.Minute
+	webhookRedeliverFor   = 7 * 24 * time.Hour
+)
+
+type assignmentChangePayload struct {
+	OaID    string                    `json:"oa_id"`
+	Changes []entity.AssignmentChange `json:"changes"`
+}
+
+// GetAssignmentChanges returns the changes of the OA made after since, paged
+// by change_id. The jobs that write changes hold the assignments lock, so
+// change_ids are committed in order and a page never skips a change committed
+// later.
+func GetAssignmentChanges(oaID string, since *time.Time, afterID int, limit int) ([]entity.AssignmentChange, error) {
+	exists, err := repository.OAIDExists(database.DB, oaID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("oa_id %s not found", oaID)
+	}
+
+	changes, err := repository.GetAssignmentChanges(database.DB, oaID, since, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment changes: %w", err)
+	}
+	return changes, nil
+}
+
+// UpdateOAWebhook sets where the OA's assignment changes are pushed and the
+// secret they are signed with.
+func UpdateOAWebhook(oaID string, wModel model.OAWebhook) error {
+	exists, err := repository.OAIDExists(database.DB, oaID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("oa_id %s not found", oaID)
+	}
+	if err := repository.UpdateOAWebhook(database.DB, oaID, wModel.WebhookURL, wModel.WebhookSecret); err != nil {
+		return fmt.Errorf("failed to update webhook of OA %s: %w", oaID, err)
+	}
+	return nil
+}
+
+// RedeliverAssignmentChanges pushes again the changes whose webhook delivery
+// failed, and records the job in job_run with the number of delivered
+// changes as its assigned count.
+func RedeliverAssignmentChanges() (*entity.JobRun, error) {
+	jobRun, err := startJobRun(constant.JOB_NAME_WEBHOOK_RETRY)
+	if err != nil {
+		return nil, err
+	}
+
+	delivered, err := redeliverAssignmentChanges(jobRun.StartedAt)
+	if err == nil {
+		jobRun.AssignedCount = util.IntToNullInt32(delivered)
+	}
+	return jobRun, finishJobRun(jobRun, err)
+}
+
+func redeliverAssignmentChanges(now time.Time) (int, error) {
+	oas, err := repository.GetAllOA(database.DB)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get all oa: %w", err)
+	}
+	webhookOAs := make(map[string]entity.OA)
+	var oaIDs []string
+	for _, oa := range oas {
+		if hasWebhook(oa) {
+			webhookOAs[oa.OAId] = oa
+			oaIDs = append(oaIDs, oa.OAId)
+		}
+	}
+
+	changes, err := repository.GetUndeliveredAssignmentChanges(database.DB, oaIDs, now.Add(-webhookRedeliverFor), now.Add(-webhookRedeliverAfter))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get undelivered assignment changes: %w", err)
+	}
+	oaChanges := make(map[string][]entity.AssignmentChange)
+	for _, change := range changes {
+		oaChanges[change.OaID] = append(oaChanges[change.OaID], change)
+	}
+
+	delivered := 0
+	for _, oaID := range oaIDs {
+		if len(oaChanges[oaID]) == 0 {
+			continue
+		}
+		oa := webhookOAs[oaID]
+		if deliverAssignmentChanges(getNullStringValue(oa.WebhookURL), getNullStringValue(oa.WebhookSecret), oaID, oaChanges[oaID]) {
+			delivered += len(oaChanges[oaID])
+		}
+	}
+	return delivered, nil
+}
+
+// hasWebhook reports whether changes can be pushed to the OA: it needs both
+// a URL and a secret to sign with.
+func hasWebhook(oa entity.OA) bool {
+	return getNullStringValue(oa.WebhookURL) != "" && getNullStringValue(oa.WebhookSecret) != ""
+}
+
+// getAssignmentChanges diffs the OA of each account before and after a move.
+// An account that moves between OAs is a loss for one and a gain for the other.
+func getAssignmentChanges(previousOA map[string]string, nextOA map[string]string, runID *string, now time.Time) []entity.AssignmentChange {
+	var accountIDs []string
+	seen := make(map[string]bool)
+	for _, oaMap := range []map[string]string{previousOA, nextOA} {
+		for accountID := range oaMap {
+			if !seen[accountID] {
+				seen[accountID] = true
+				accountIDs = append(accountIDs, accountID)
+			}
+		}
+	}
+	sort.Strings(accountIDs)
+
+	var changes []entity.AssignmentChange
+	for _, accountID := range accountIDs {
+		previous, next := previousOA[accountID], nextOA[accountID]
+		if previous == next {
+			continue
+		}
+		if previous != "" {
+			changes = append(changes, entity.AssignmentChange{
+				OaID:       previous,
+				AccountID:  accountID,
+				ChangeType: constant.CHANGE_TYPE_LOST,
+				RunID:      ToNullString(runID),
+				CreatedAt:  now,
+			})
+		}
+		if next != "" {
+			changes = append(changes, entity.AssignmentChange{
+				OaID:       next,
+				AccountID:  accountID,
+				ChangeType: constant.CHANGE_TYPE_GAINED,
+				RunID:      ToNullString(runID),
+				CreatedAt:  now,
+			})
+		}
+	}
+	return changes
+}
+
+func saveAssignmentChanges(tx *gorm.DB, changes []entity.AssignmentChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := tx.CreateInBatches(changes, 1000).Error; err != nil {
+		return fmt.Errorf("failed to insert assignment changes: %w", err)
+	}
+	return nil
+}
+
+// notifyAssignmentChanges pushes the changes of each OA to its webhook in the
+// background. Call it once the changes are committed.
+func notifyAssignmentChanges(changes []entity.AssignmentChange) {
+	if len(changes) == 0 {
+		return
+	}
+	go func() {
+		oas, err := repository.GetAllOA(database.DB)
+		if err != nil {
+			log.Printf("❌ Failed to load OAs for assignment change webhooks: %v", err)
+			return
+		}
+		oaChanges := make(map[string][]entity.AssignmentChange)
+		for _, change := range changes {
+			oaChanges[change.OaID] = append(oaChanges[change.OaID], change)
+		}
+		for _, oa := range oas {
+			if len(oaChanges[oa.OAId]) == 0 {
+				continue
+			}
+			if !hasWebhook(oa) {
+				if getNullStringValue(oa.WebhookURL) != "" {
+					log.Printf("⚠️ OA %s has a webhook_url but no webhook_secret, assignment changes not sent", oa.OAId)
+				}
+				continue
+			}
+			deliverAssignmentChanges(getNullStringValue(oa.WebhookURL), getNullStringValue(oa.WebhookSecret), oa.OAId, oaChanges[oa.OAId])
+		}
+	}()
+}
+
+// deliverAssignmentChanges posts the changes to the webhook, retrying on
+// failure, and marks them delivered. It reports whether the OA took them.
+func deliverAssignmentChanges(webhookURL string, secret string, oaID string, changes []entity.AssignmentChange) bool {
+	body, err := json.Marshal(assignmentChangePayload{OaID: oaID, Changes: changes})
+	if err != nil {
+		log.Printf("❌ Failed to encode assignment changes for OA %s: %v", oaID, err)
+		return false
+	}
+
+	delay := webhookRetryDelay
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		err = postWebhook(webhookURL, secret, body)
+		if err == nil {
+			break
+		}
+		log.Printf("⚠️ Assignment change webhook for OA %s failed (attempt %d/%d): %v", oaID, attempt, webhookMaxAttempts, err)
+		if attempt < webhookMaxAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	if err != nil {
+		log.Printf("❌ Gave up delivering %d assignment changes to OA %s", len(changes), oaID)
+		return false
+	}
+
+	var changeIDs []int
+	for _, change := range changes {
+		changeIDs = append(changeIDs, change.ChangeID)
+	}
+	if err := repository.MarkAssignmentChangesDelivered(database.DB, changeIDs, time.Now()); err != nil {
+		log.Printf("❌ Failed to mark assignment changes of OA %s delivered: %v", oaID, err)
+	}
+	return true
+}
+
+func postWebhook(webhookURL string, secret string, body []byte) error {
+	if secret == "" {
+		return fmt.Errorf("webhook_secret is not set")
+	}
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Signature", signWebhook(secret, timestamp, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func signWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"oa_id":"OA1","changes":[]}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	if got := signWebhook("secret", "1700000000", body); got != want {
+		t.Errorf("signWebhook() = %s, want %s", got, want)
+	}
+	if signWebhook("other", "1700000000", body) == want {
+		t.Error("signWebhook() gave the same signature for another secret")
+	}
+	if signWebhook("secret", "1700000001", body) == want {
+		t.Error("signWebhook() gave the same signature for another timestamp")
+	}
+}
+
+func TestPostWebhook(t *testing.T) {
+	body := []byte(`{"oa_id":"OA1","changes":[]}`)
+
+	tests := []struct {
+		name    string
+		secret  string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", secret: "secret", status: http.StatusOK},
+		{name: "accepted without content", secret: "secret", status: http.StatusNoContent},
+		{name: "rejected", secret: "secret", status: http.StatusUnauthorized, wantErr: true},
+		{name: "server error", secret: "secret", status: http.StatusInternalServerError, wantErr: true},
+		{name: "no secret", secret: "", status: http.StatusOK, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				received, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatalf("failed to read body: %v", err)
+				}
+				if r.Method != http.MethodPost {
+					t.Errorf("method = %s, want POST", r.Method)
+				}
+				if got := r.Header.Get("Content-Type"); got != "application/json" {
+					t.Errorf("Content-Type = %s, want application/json", got)
+				}
+				if string(received) != string(body) {
+					t.Errorf("body = %s, want %s", received, body)
+				}
+				timestamp := r.Header.Get("X-Timestamp")
+				if timestamp == "" {
+					t.Error("X-Timestamp is missing")
+				}
+				if got := r.Header.Get("X-Signature"); got != signWebhook(tt.secret, timestamp, received) {
+					t.Errorf("X-Signature = %s does not match the body", got)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := postWebhook(server.URL, tt.secret, body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("postWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.secret == "" && called {
+				t.Error("postWebhook() sent an unsigned request")
+			}
+		})
+	}
+}
+
+func TestPostWebhookUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	if err := postWebhook(url, "secret", []byte(`{}`)); err == nil {
+		t.Error("postWebhook() to a closed server returned no error")
+	}
+}
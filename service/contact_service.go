@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"nhj-poc/repository"
+	"strings"
+	"time"
+)
+
+func InsertContactLog(cModel model.ContactLog) (*entity.ContactLog, error) {
+	exists, err := repository.AccountIDExists(database.DB, cModel.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("account_id not found")
+	}
+	exists, err = repository.OAIDExists(database.DB, cModel.OaID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("oa_id %s not found", cModel.OaID)
+	}
+
+	contactedAt := time.Now()
+	if cModel.ContactedAt != nil {
+		if cModel.ContactedAt.After(contactedAt) {
+			return nil, fmt.Errorf("contacted_at cannot be in the future")
+		}
+		contactedAt = *cModel.ContactedAt
+	}
+
+	contactLog := entity.ContactLog{
+		AccountID:   cModel.AccountID,
+		OaID:        cModel.OaID,
+		ContactType: strings.ToUpper(strings.TrimSpace(cModel.ContactType)),
+		Note:        ToNullString(cModel.Note),
+		ContactedAt: contactedAt,
+	}
+	if err := database.DB.Create(&contactLog).Error; err != nil {
+		return nil, fmt.Errorf("failed to insert contact log: %w", err)
+	}
+	return &contactLog, nil
+}
+
+func GetContactLogs(accountID string) ([]entity.ContactLog, error) {
+	contactLogs, err := repository.GetContactLogs(database.DB, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact logs: %w", err)
+	}
+	return contactLogs, nil
+}
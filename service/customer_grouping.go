@@ -0,0 +1,135 @@
+package service
+
+import (
+	"database/sql"
+	"nhj-poc/domain/entity"
+	"sort"
+)
+
+// groupAccountsByCustomer folds the accounts of each customer into one
+// account that stands for the whole relationship. The lead is the largest
+// account of the dominant product, the product with the most outstanding;
+// it carries the combined outstanding and overdue amounts and the worst days
+// past due, which decides the bucket the group is assigned in. Accounts with
+// an override are left on their own. Each bucket is sorted again by
+// outstanding, largest first, as the accounts are loaded, since the leads now
+// weigh the whole customer.
+func groupAccountsByCustomer(accountsByBucket map[int][]entity.Account, buckets []entity.Bucket, overrides map[string]entity.AssignmentOverride) map[int][]entity.Account {
+	grouped := make(map[int][]entity.Account)
+	customerAccounts := make(map[string][]entity.Account)
+	var customerIDs []string
+	for _, bucket := range buckets {
+		for _, account := range accountsByBucket[bucket.BucketID] {
+			if _, locked := overrides[account.AccountID]; locked || account.CustomerID == "" {
+				grouped[bucket.BucketID] = append(grouped[bucket.BucketID], account)
+				continue
+			}
+			if _, ok := customerAccounts[account.CustomerID]; !ok {
+				customerIDs = append(customerIDs, account.CustomerID)
+			}
+			customerAccounts[account.CustomerID] = append(customerAccounts[account.CustomerID], account)
+		}
+	}
+
+	for _, customerID := range customerIDs {
+		lead := getCustomerLead(customerAccounts[customerID])
+		if bucketID, ok := resolveBucket(lead.DaysPastDue, buckets); ok {
+			grouped[bucketID] = append(grouped[bucketID], lead)
+		}
+	}
+	for _, accounts := range grouped {
+		sort.SliceStable(accounts, func(i, j int) bool {
+			oi, oj := getOutstandingAmount(accounts[i]), getOutstandingAmount(accounts[j])
+			if oi != oj {
+				return oi > oj
+			}
+			return accounts[i].AccountID < accounts[j].AccountID
+		})
+	}
+	return grouped
+}
+
+func getCustomerLead(accounts []entity.Account) entity.Account {
+	if len(accounts) == 1 {
+		return accounts[0]
+	}
+
+	productOutstanding := make(map[string]int64)
+	for _, account := range accounts {
+		productOutstanding[account.ProductType.String] += getAmount(account.OutstandingAmount)
+	}
+	sorted := make([]entity.Account, len(accounts))
+	copy(sorted, accounts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		pi, pj := sorted[i].ProductType.String, sorted[j].ProductType.String
+		if productOutstanding[pi] != productOutstanding[pj] {
+			return productOutstanding[pi] > productOutstanding[pj]
+		}
+		if pi != pj {
+			return pi < pj
+		}
+		return getAmount(sorted[i].OutstandingAmount) > getAmount(sorted[j].OutstandingAmount)
+	})
+
+	lead := sorted[0]
+	var outstanding, overdue int64
+	var daysPastDue int32
+	for _, account := range sorted {
+		outstanding += getAmount(account.OutstandingAmount)
+		overdue += getAmount(account.OverdueAmount)
+		daysPastDue = max(daysPastDue, account.DaysPastDue.Int32)
+	}
+	lead.GroupOutstanding = outstanding
+	lead.GroupOverdue = overdue
+	lead.DaysPastDue = &sql.NullInt32{Int32: daysPastDue, Valid: true}
+	lead.Members = sorted[1:]
+	return lead
+}
+
+// getOutstandingAmount is the outstanding of the account, or of the whole
+// group when it leads one.
+func getOutstandingAmount(account entity.Account) int64 {
+	if len(account.Members) > 0 {
+		return account.GroupOutstanding
+	}
+	return getAmount(account.OutstandingAmount)
+}
+
+// getOverdueAmount is the overdue of the account, or of the whole group when
+// it leads one.
+func getOverdueAmount(account entity.Account) int64 {
+	if len(account.Members) > 0 {
+		return account.GroupOverdue
+	}
+	return getAmount(account.OverdueAmount)
+}
+
+// getGroupSize is the number of accounts an account stands for.
+func getGroupSize(account entity.Account) int {
+	return 1 + len(account.Members)
+}
+
+// expandCustomerGroups gives every member of a grouped account the same
+// assignment as its lead.
+func expandCustomerGroups(assignments []entity.Assignments, accounts []entity.Account) []entity.Assignments {
+	accountMap := make(map[string]entity.Account)
+	for _, account := range accounts {
+		if len(account.Members) > 0 {
+			accountMap[account.AccountID] = account
+		}
+	}
+	if len(accountMap) == 0 {
+		return assignments
+	}
+
+	expanded := make([]entity.Assignments, 0, len(assignments))
+	for _, assignment := range assignments {
+		expanded = append(expanded, assignment)
+		for _, member := range accountMap[assignment.AccountID.String].Members {
+			memberAssignment := assignment
+			memberAssignment.AccountID = ToNullString(&member.AccountID)
+			expanded = append(expanded, memberAssignment)
+		}
+	}
+	return expanded
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"nhj-poc/repository"
+	"nhj-poc/util"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func GetExperiments() ([]entity.Experiment, error) {
+	experiments, err := repository.GetAllExperiment(database.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all experiment: %w", err)
+	}
+	return experiments, nil
+}
+
+// CreateExperiment starts an experiment. Only one experiment runs at a time.
+func CreateExperiment(eModel model.Experiment) (*entity.Experiment, error) {
+	experiment := entity.Experiment{
+		ExperimentName:       strings.TrimSpace(eModel.ExperimentName),
+		ChampionAssignBy:     eModel.ChampionAssignBy,
+		ChallengerAssignBy:   eModel.ChallengerAssignBy,
+		ChallengerPercentage: eModel.ChallengerPercentage,
+		Active:               true,
+		StartedAt:            time.Now(),
+	}
+	if experiment.ExperimentName == "" {
+		return nil, fmt.Errorf("experiment_name is required")
+	}
+	if _, err := GetAssignmentStrategy(experiment.ChampionAssignBy); err != nil {
+		return nil, err
+	}
+	if _, err := GetAssignmentStrategy(experiment.ChallengerAssignBy); err != nil {
+		return nil, err
+	}
+	if experiment.ChampionAssignBy == experiment.ChallengerAssignBy {
+		return nil, fmt.Errorf("champion_assign_by and challenger_assign_by must differ")
+	}
+	// Like the allocation percentages, a fraction of 1
+	if experiment.ChallengerPercentage <= 0 || experiment.ChallengerPercentage >= 1 {
+		return nil, fmt.Errorf("challenger_percentage must be between 0 and 1")
+	}
+
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	active, err := repository.GetActiveExperiment(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active experiment: %w", err)
+	}
+	if active != nil {
+		return nil, fmt.Errorf("experiment_id %d is still active", active.ExperimentID)
+	}
+	if err := tx.Create(&experiment).Error; err != nil {
+		return nil, fmt.Errorf("failed to insert experiment: %w", err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &experiment, nil
+}
+
+// StopExperiment ends the experiment; the next run assigns every account with
+// the champion again.
+func StopExperiment(experimentID int) (*entity.Experiment, error) {
+	experiment, err := repository.GetExperimentByExperimentID(database.DB, experimentID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("experiment_id %d not found", experimentID)
+		}
+		return nil, err
+	}
+	if !experiment.Active {
+		return nil, fmt.Errorf("experiment_id %d is already stopped", experimentID)
+	}
+
+	now := time.Now()
+	experiment.Active = false
+	experiment.EndedAt = &now
+	if err := database.DB.
+		Model(&entity.Experiment{}).
+		Where("experiment_id = ?", experimentID).
+		Updates(map[string]interface{}{
+			"active":   experiment.Active,
+			"ended_at": experiment.EndedAt,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update experiment %d: %w", experimentID, err)
+	}
+	return experiment, nil
+}
+
+// GetExperimentResults compares the collection rate of the accounts each arm
+// held: what was paid while the arm held them over their overdue amount.
+func GetExperimentResults(experimentID int) (*model.ExperimentResult, error) {
+	experiment, err := repository.GetExperimentByExperimentID(database.DB, experimentID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("experiment_id %d not found", experimentID)
+		}
+		return nil, err
+	}
+	overdue, err := repository.GetExperimentOverdue(database.DB, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment overdue: %w", err)
+	}
+	collected, err := repository.GetExperimentCollected(database.DB, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment collected: %w", err)
+	}
+
+	arms := map[string]*model.ExperimentArmResult{
+		constant.EXPERIMENT_ARM_CHAMPION:   {Arm: constant.EXPERIMENT_ARM_CHAMPION, AssignBy: experiment.ChampionAssignBy},
+		constant.EXPERIMENT_ARM_CHALLENGER: {Arm: constant.EXPERIMENT_ARM_CHALLENGER, AssignBy: experiment.ChallengerAssignBy},
+	}
+	for _, row := range overdue {
+		if arm, ok := arms[row.Arm]; ok {
+			arm.AccountCount = row.AccountCount
+			arm.OverdueAmount = row.Amount
+		}
+	}
+	for _, row := range collected {
+		if arm, ok := arms[row.Arm]; ok {
+			arm.Collected = row.Amount
+		}
+	}
+
+	result := &model.ExperimentResult{ExperimentID: experimentID}
+	for _, name := range []string{constant.EXPERIMENT_ARM_CHAMPION, constant.EXPERIMENT_ARM_CHALLENGER} {
+		arm := arms[name]
+		if arm.OverdueAmount > 0 {
+			arm.CollectionRate = float64(arm.Collected) / float64(arm.OverdueAmount)
+		}
+		result.Arms = append(result.Arms, *arm)
+	}
+	result.Uplift = arms[constant.EXPERIMENT_ARM_CHALLENGER].CollectionRate - arms[constant.EXPERIMENT_ARM_CHAMPION].CollectionRate
+	return result, nil
+}
+
+// getExperimentArm hashes the experiment_id and account_id to a point in
+// [0, 1) so an account stays in the same arm on every run of the experiment,
+// while each new experiment draws its arms afresh.
+func getExperimentArm(experiment *entity.Experiment, accountID string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(fmt.Sprintf("%d|%s", experiment.ExperimentID, accountID)))
+	if float64(hash.Sum32()%10000)/10000 < experiment.ChallengerPercentage {
+		return constant.EXPERIMENT_ARM_CHALLENGER
+	}
+	return constant.EXPERIMENT_ARM_CHAMPION
+}
+
+// assignWithExperiment splits the accounts between the arms and lets each
+// strategy allocate its own part. The capacity is split the same way so
+// neither arm can use up the OAs before the other one runs; what is left of
+// both shares is put back together afterwards.
+func assignWithExperiment(champion AssignmentStrategy, challenger AssignmentStrategy, experiment *entity.Experiment, input BucketInput) BucketResult {
+	if challenger == nil {
+		return champion.AssignBucket(input)
+	}
+
+	var championAccounts []entity.Account
+	var challengerAccounts []entity.Account
+	for _, account := range input.Accounts {
+		if getExperimentArm(experiment, account.AccountID) == constant.EXPERIMENT_ARM_CHALLENGER {
+			challengerAccounts = append(challengerAccounts, account)
+		} else {
+			championAccounts = append(championAccounts, account)
+		}
+	}
+
+	challengerCapacity, championCapacity := splitCapacity(input.Capacity, experiment.ChallengerPercentage)
+
+	challengerInput := input
+	challengerInput.Accounts = challengerAccounts
+	challengerInput.Capacity = challengerCapacity
+	result := challenger.AssignBucket(challengerInput)
+
+	championInput := input
+	championInput.Accounts = championAccounts
+	championInput.Capacity = championCapacity
+	championResult := champion.AssignBucket(championInput)
+	result.Assignments = append(result.Assignments, championResult.Assignments...)
+	result.Uncovered = append(result.Uncovered, championResult.Uncovered...)
+
+	for oaID, capacity := range input.Capacity {
+		capacity.Capacity = challengerCapacity[oaID].Capacity + championCapacity[oaID].Capacity
+		for productType := range capacity.ProductCapacity {
+			capacity.ProductCapacity[productType] = challengerCapacity[oaID].ProductCapacity[productType] + championCapacity[oaID].ProductCapacity[productType]
+		}
+		input.Capacity[oaID] = capacity
+	}
+	return result
+}
+
+// splitCapacity gives the challenger its percentage of every OA's capacity,
+// rounded, and the champion the rest.
+func splitCapacity(capacityOA map[string]CapacityOA, challengerPercentage float64) (map[string]CapacityOA, map[string]CapacityOA) {
+	challengerCapacity := copyCapacity(capacityOA)
+	championCapacity := copyCapacity(capacityOA)
+	for oaID, capacity := range capacityOA {
+		challenger := challengerCapacity[oaID]
+		champion := championCapacity[oaID]
+		challenger.Capacity = int(math.Round(float64(capacity.Capacity) * challengerPercentage))
+		champion.Capacity = capacity.Capacity - challenger.Capacity
+		for productType, count := range capacity.ProductCapacity {
+			challenger.ProductCapacity[productType] = int(math.Round(float64(count) * challengerPercentage))
+			champion.ProductCapacity[productType] = count - challenger.ProductCapacity[productType]
+		}
+		challengerCapacity[oaID] = challenger
+		championCapacity[oaID] = champion
+	}
+	return challengerCapacity, championCapacity
+}
+
+// tagExperimentArm records the experiment and the arm of the account on each
+// assignment.
+func tagExperimentArm(assignments []entity.Assignments, experiment *entity.Experiment) {
+	for i, assignment := range assignments {
+		arm := getExperimentArm(experiment, assignment.AccountID.String)
+		assignments[i].ExperimentID = util.IntToNullInt32(experiment.ExperimentID)
+		assignments[i].Arm = ToNullString(&arm)
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"nhj-poc/repository"
+	"nhj-poc/util"
+	"time"
+)
+
+// uploadStaleAfter bounds how long a RUNNING upload blocks the scheduled run,
+// so an upload that died without finishing its job_run row does not block it
+// forever.
+const uploadStaleAfter = 2 * time.Hour
+
+func GetJobRuns(jobName string, limit int) ([]entity.JobRun, error) {
+	runs, err := repository.GetJobRuns(database.DB, jobName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job runs: %w", err)
+	}
+	return runs, nil
+}
+
+// RunScheduledAssignments runs the assignment for assignBy and records it in
+// job_run. The run is skipped while an Excel upload is still in progress.
+func RunScheduledAssignments(assignBy string, options model.AssignmentOptions) (*entity.JobRun, error) {
+	jobRun, err := startJobRun(constant.JOB_NAME_ASSIGNMENT_RUN)
+	if err != nil {
+		return nil, err
+	}
+
+	uploading, err := repository.JobRunning(database.DB, constant.JOB_NAME_EXCEL_UPLOAD, constant.JOB_STATUS_RUNNING, jobRun.StartedAt.Add(-uploadStaleAfter))
+	if err != nil {
+		return jobRun, finishJobRun(jobRun, err)
+	}
+	if uploading {
+		jobRun.Status = constant.JOB_STATUS_SKIPPED
+		skipped := "excel upload in progress"
+		jobRun.Error = ToNullString(&skipped)
+		return jobRun, finishJobRun(jobRun, nil)
+	}
+
+	result, err := runAssignments(assignBy, options)
+	if err == nil {
+		assigned, unassigned := 0, 0
+		for _, count := range result.BucketCounts {
+			assigned += count
+		}
+		for _, count := range result.UnassignedCounts {
+			unassigned += count
+		}
+		jobRun.AssignedCount = util.IntToNullInt32(assigned)
+		jobRun.UnassignedCount = util.IntToNullInt32(unassigned)
+		jobRun.RunID = ToNullString(&result.RunID)
+	}
+	return jobRun, finishJobRun(jobRun, err)
+}
+
+func startJobRun(jobName string) (*entity.JobRun, error) {
+	jobRun := entity.JobRun{
+		JobName:   jobName,
+		Status:    constant.JOB_STATUS_RUNNING,
+		StartedAt: time.Now(),
+	}
+	if err := database.DB.Create(&jobRun).Error; err != nil {
+		return nil, fmt.Errorf("failed to insert job run: %w", err)
+	}
+	return &jobRun, nil
+}
+
+// finishJobRun stamps the end time and the outcome of jobErr on the job run.
+// It returns jobErr so callers can hand it straight back.
+func finishJobRun(jobRun *entity.JobRun, jobErr error) error {
+	finishedAt := time.Now()
+	jobRun.FinishedAt = &finishedAt
+	if jobErr != nil {
+		message := jobErr.Error()
+		jobRun.Status = constant.JOB_STATUS_FAILED
+		jobRun.Error = ToNullString(&message)
+	} else if jobRun.Status == constant.JOB_STATUS_RUNNING {
+		jobRun.Status = constant.JOB_STATUS_SUCCESS
+	}
+
+	if err := database.DB.
+		Model(&entity.JobRun{}).
+		Where("job_run_id = ?", jobRun.JobRunID).
+		Updates(map[string]interface{}{
+			"status":           jobRun.Status,
+			"finished_at":      jobRun.FinishedAt,
+			"assigned_count":   jobRun.AssignedCount,
+			"unassigned_count": jobRun.UnassignedCount,
+			"run_id":           jobRun.RunID,
+			"error":            jobRun.Error,
+		}).Error; err != nil {
+		log.Printf("failed to update job run %d: %v", jobRun.JobRunID, err)
+	}
+	return jobErr
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"database/sql"
+	"math"
+	"nhj-poc/constant"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+)
+
+const earthRadiusKm = 6371.0
+
+type nearestOAStrategy struct{}
+
+func init() {
+	RegisterAssignmentStrategy(nearestOAStrategy{})
+}
+
+func (nearestOAStrategy) AssignBy() string {
+	return constant.ASSIGN_BY_NEAREST_OA
+}
+
+func (nearestOAStrategy) Description() string {
+	return "Nearest OA with capacity left to the centroid of the customer's postal code"
+}
+
+func (nearestOAStrategy) AssignBucket(input BucketInput) BucketResult {
+	assignments, uncovered := assignBucketByNearestOA(input.Accounts, input.OAs, input.Customers, input.Centroids, input.Capacity)
+	return BucketResult{
+		Assignments: assignments,
+		Uncovered:   uncovered,
+	}
+}
+
+// assignBucketByNearestOA geocodes each customer to the centroid of their
+// current postal code, or the register one when the current one is empty, and
+// gives the account to the closest OA that still has capacity for the
+// product. OAs without a location are skipped and customers whose postal code
+// has no centroid are reported as uncovered.
+func assignBucketByNearestOA(accounts []entity.Account, oas []entity.OA, customerMap map[string]entity.Customer, centroidMap map[string]entity.PostalCodeCentroid, capacityOA map[string]CapacityOA) ([]entity.Assignments, []model.UncoveredAccount) {
+	var locatedOAs []entity.OA
+	for _, oa := range oas {
+		if oa.LocationLatitude != nil && oa.LocationLatitude.Valid &&
+			oa.LocationLongitude != nil && oa.LocationLongitude.Valid {
+			locatedOAs = append(locatedOAs, oa)
+		}
+	}
+
+	var assignments []entity.Assignments
+	var uncovered []model.UncoveredAccount
+	nearestOA := constant.ASSIGN_BY_NEAREST_OA
+	for _, account := range accounts {
+		customerPostalCode := getCustomerPostalCode(customerMap[account.CustomerID])
+		centroid, ok := centroidMap[customerPostalCode]
+		if !ok {
+			uncovered = append(uncovered, model.UncoveredAccount{
+				AccountID:  account.AccountID,
+				PostalCode: customerPostalCode,
+			})
+			continue
+		}
+
+		var assignOaID string = ""
+		bestDistance := math.Inf(1)
+		for _, oa := range locatedOAs {
+			if !hasProductCapacity(capacityOA[oa.OAId], account) {
+				continue
+			}
+			distance := getDistanceKm(centroid.Latitude, centroid.Longitude, oa.LocationLatitude.Float64, oa.LocationLongitude.Float64)
+			if distance < bestDistance {
+				assignOaID = oa.OAId
+				bestDistance = distance
+			}
+		}
+
+		if assignOaID == "" {
+			continue
+		}
+		takeCapacity(capacityOA, assignOaID, account)
+		assignments = append(assignments, entity.Assignments{
+			AccountID:  ToNullString(&account.AccountID),
+			OaID:       ToNullString(&assignOaID),
+			AssignBy:   ToNullString(&nearestOA),
+			DistanceKm: &sql.NullFloat64{Float64: math.Round(bestDistance*100) / 100, Valid: true},
+		})
+	}
+
+	return assignments, uncovered
+}
+
+// getDistanceKm is the great-circle distance between two coordinates.
+func getDistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 {
+		return degrees * math.Pi / 180
+	}
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"nhj-poc/repository"
+	"strings"
+	"time"
+)
+
+func CreateOverride(oModel model.AssignmentOverride) (*entity.AssignmentOverride, error) {
+	now := time.Now()
+	if strings.TrimSpace(oModel.Reason) == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	if oModel.ExpiresAt != nil && !oModel.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+
+	exists, err := repository.AccountIDExists(database.DB, oModel.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("account_id not found")
+	}
+
+	switch oModel.OverrideType {
+	case constant.OVERRIDE_TYPE_PIN, constant.OVERRIDE_TYPE_EXCLUDE:
+		if oModel.OaID == nil || *oModel.OaID == "" {
+			return nil, fmt.Errorf("oa_id is required to %s an account", strings.ToLower(oModel.OverrideType))
+		}
+		exists, err := repository.OAIDExists(database.DB, *oModel.OaID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("oa_id not found")
+		}
+	case constant.OVERRIDE_TYPE_LOCK:
+		if oModel.OaID != nil && *oModel.OaID != "" {
+			return nil, fmt.Errorf("a locked account cannot have an oa_id")
+		}
+	default:
+		return nil, fmt.Errorf("override_type must be %s, %s or %s", constant.OVERRIDE_TYPE_PIN, constant.OVERRIDE_TYPE_LOCK, constant.OVERRIDE_TYPE_EXCLUDE)
+	}
+
+	active, err := repository.ActiveOverrideExists(database.DB, oModel.AccountID, now)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, fmt.Errorf("account %s already has an active override", oModel.AccountID)
+	}
+
+	override := entity.AssignmentOverride{
+		AccountID:    oModel.AccountID,
+		OverrideType: oModel.OverrideType,
+		OaID:         ToNullString(oModel.OaID),
+		Reason:       oModel.Reason,
+		ExpiresAt:    oModel.ExpiresAt,
+		CreatedAt:    now,
+	}
+	if err := database.DB.Create(&override).Error; err != nil {
+		return nil, fmt.Errorf("failed to insert override: %w", err)
+	}
+	return &override, nil
+}
+
+// GetOverrides lists the active overrides, or every override when
+// includeExpired is set.
+func GetOverrides(includeExpired bool) ([]entity.AssignmentOverride, error) {
+	if includeExpired {
+		return repository.GetAllOverrides(database.DB)
+	}
+	return repository.GetActiveOverrides(database.DB, time.Now())
+}
+
+func DeleteOverride(overrideID int) error {
+	rowsAffected, err := repository.DeleteOverride(database.DB, overrideID)
+	if err != nil {
+		return fmt.Errorf("failed to delete override %d: %w", overrideID, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("override_id %d not found", overrideID)
+	}
+	return nil
+}
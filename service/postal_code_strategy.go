@@ -0,0 +1,102 @@
+package service
+
+import (
+	"nhj-poc/constant"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"strings"
+)
+
+type postalCodeStrategy struct{}
+
+func init() {
+	RegisterAssignmentStrategy(postalCodeStrategy{})
+}
+
+func (postalCodeStrategy) AssignBy() string {
+	return constant.ASSIGN_BY_POSTAL_CODE
+}
+
+func (postalCodeStrategy) Description() string {
+	return "OA whose postal list covers the customer's postal code, within each OA's product percentage"
+}
+
+func (postalCodeStrategy) AssignBucket(input BucketInput) BucketResult {
+	assignments, uncovered := assignBucketByPostalCode(input.Accounts, input.OAs, input.Customers, input.Capacity)
+	return BucketResult{
+		Assignments: assignments,
+		Uncovered:   uncovered,
+	}
+}
+
+// assignBucketByPostalCode gives each account to an OA whose postal list covers
+// the customer's current postal code, or the register postal code when the
+// current one is empty. Among the covering OAs the one with the most capacity
+// left for the product wins.
+func assignBucketByPostalCode(accounts []entity.Account, oas []entity.OA, customerMap map[string]entity.Customer, capacityOA map[string]CapacityOA) ([]entity.Assignments, []model.UncoveredAccount) {
+	territoryOA := make(map[string][]string)
+	for _, oa := range oas {
+		if oa.PostalList == nil || !oa.PostalList.Valid {
+			continue
+		}
+		for _, postalCode := range parsePostalList(oa.PostalList.String) {
+			territoryOA[postalCode] = append(territoryOA[postalCode], oa.OAId)
+		}
+	}
+
+	var assignments []entity.Assignments
+	var uncovered []model.UncoveredAccount
+	postalCode := constant.ASSIGN_BY_POSTAL_CODE
+	for _, account := range accounts {
+		customerPostalCode := getCustomerPostalCode(customerMap[account.CustomerID])
+		candidates, covered := territoryOA[customerPostalCode]
+		if !covered {
+			uncovered = append(uncovered, model.UncoveredAccount{
+				AccountID:  account.AccountID,
+				PostalCode: customerPostalCode,
+			})
+		}
+
+		var assignOaID string = ""
+		bestCapacity := 0
+		for _, oaID := range candidates {
+			capacity, ok := capacityOA[oaID]
+			if !ok || !hasProductCapacity(capacity, account) {
+				continue
+			}
+			productCapacity := capacity.ProductCapacity[account.ProductType.String]
+			if productCapacity > bestCapacity {
+				assignOaID = oaID
+				bestCapacity = productCapacity
+			}
+		}
+
+		if assignOaID == "" {
+			continue
+		}
+		takeCapacity(capacityOA, assignOaID, account)
+		assignments = append(assignments, entity.Assignments{
+			AccountID: ToNullString(&account.AccountID),
+			OaID:      ToNullString(&assignOaID),
+			AssignBy:  ToNullString(&postalCode),
+		})
+	}
+
+	return assignments, uncovered
+}
+
+func getCustomerPostalCode(customer entity.Customer) string {
+	if customer.CurrentPostalCode != nil && strings.TrimSpace(customer.CurrentPostalCode.String) != "" {
+		return strings.TrimSpace(customer.CurrentPostalCode.String)
+	}
+	if customer.RegisterPostalCode != nil {
+		return strings.TrimSpace(customer.RegisterPostalCode.String)
+	}
+	return ""
+}
+
+func parsePostalList(postalList string) []string {
+	return strings.FieldsFunc(postalList, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n'
+	})
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"nhj-poc/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func GetProductTypes() ([]entity.ProductType, error) {
+	productTypes, err := repository.GetAllProductType(database.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all product type: %w", err)
+	}
+	return productTypes, nil
+}
+
+func CreateProductType(pModel model.ProductType) (*entity.ProductType, error) {
+	productType := entity.ProductType{
+		ProductType: strings.TrimSpace(pModel.ProductType),
+		ProductName: ToNullString(pModel.ProductName),
+		Active:      pModel.Active,
+	}
+	if productType.ProductType == "" {
+		return nil, fmt.Errorf("product_type is required")
+	}
+	exists, err := repository.ProductTypeExists(database.DB, productType.ProductType)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("product_type %s already exists", productType.ProductType)
+	}
+	if err := database.DB.Create(&productType).Error; err != nil {
+		return nil, fmt.Errorf("failed to insert product type: %w", err)
+	}
+	return &productType, nil
+}
+
+func UpdateProductType(productTypeID string, pModel model.ProductType) (*entity.ProductType, error) {
+	productType := entity.ProductType{
+		ProductType: productTypeID,
+		ProductName: ToNullString(pModel.ProductName),
+		Active:      pModel.Active,
+	}
+	result := database.DB.
+		Model(&entity.ProductType{}).
+		Where("product_type = ?", productTypeID).
+		Updates(map[string]interface{}{
+			"product_name": productType.ProductName,
+			"active":       productType.Active,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update product type %s: %w", productTypeID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("product_type %s not found", productTypeID)
+	}
+	return &productType, nil
+}
+
+func GetOAAllocations(oaID string) ([]entity.OAProductAllocation, error) {
+	allocations, err := repository.GetOAProductAllocation(database.DB, oaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allocations of OA %s: %w", oaID, err)
+	}
+	return allocations, nil
+}
+
+// ReplaceOAAllocations swaps the allocations of the OA for the given set.
+func ReplaceOAAllocations(oaID string, aModels []model.OAProductAllocation) ([]entity.OAProductAllocation, error) {
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	exists, err := repository.OAIDExists(tx, oaID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("oa_id %s not found", oaID)
+	}
+
+	allocations := make([]entity.OAProductAllocation, 0, len(aModels))
+	seen := make(map[string]bool)
+	for _, aModel := range aModels {
+		if err := validateAllocation(tx, aModel); err != nil {
+			return nil, err
+		}
+		bucketKey := "all"
+		if aModel.BucketID != nil {
+			bucketKey = fmt.Sprint(*aModel.BucketID)
+		}
+		key := aModel.ProductType + "|" + bucketKey
+		if seen[key] {
+			return nil, fmt.Errorf("product_type %s is allocated twice for bucket %s", aModel.ProductType, bucketKey)
+		}
+		seen[key] = true
+
+		allocation := entity.OAProductAllocation{
+			OAId:        oaID,
+			ProductType: aModel.ProductType,
+			BucketID:    &sql.NullInt32{Valid: false},
+			Percentage:  aModel.Percentage,
+		}
+		if aModel.BucketID != nil {
+			allocation.BucketID = &sql.NullInt32{Int32: int32(*aModel.BucketID), Valid: true}
+		}
+		allocations = append(allocations, allocation)
+	}
+
+	if err := repository.DeleteOAProductAllocation(tx, oaID); err != nil {
+		return nil, fmt.Errorf("failed to delete allocations of OA %s: %w", oaID, err)
+	}
+	if len(allocations) > 0 {
+		if err := tx.Create(&allocations).Error; err != nil {
+			return nil, fmt.Errorf("failed to insert allocations: %w", err)
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return allocations, nil
+}
+
+func validateAllocation(db *gorm.DB, aModel model.OAProductAllocation) error {
+	exists, err := repository.ProductTypeExists(db, aModel.ProductType)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("product_type %q not found", aModel.ProductType)
+	}
+	if aModel.Percentage < 0 || aModel.Percentage > 1 {
+		return fmt.Errorf("percentage of %s must be between 0 and 1", aModel.ProductType)
+	}
+	if aModel.BucketID != nil {
+		if _, err := repository.GetBucketByBucketID(db, *aModel.BucketID); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("bucket_id %d not found", *aModel.BucketID)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// loadProductTypes returns the active product types the engine allocates.
+// Until the product_type table is filled it falls back to C2C and CRL.
+func loadProductTypes(db *gorm.DB) ([]string, error) {
+	productTypes, err := repository.GetActiveProductType(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active product types: %w", err)
+	}
+	if len(productTypes) == 0 {
+		return []string{constant.PRODUCT_TYPE_C2C, constant.PRODUCT_TYPE_CRL}, nil
+	}
+	var names []string
+	for _, productType := range productTypes {
+		names = append(names, productType.ProductType)
+	}
+	return names, nil
+}
+
+// loadOAs returns every OA with its OA level allocations resolved, plus the
+// per bucket allocations. The legacy c2c/crl columns count as allocations of
+// C2C and CRL unless an oa_product_allocation row overrides them.
+func loadOAs(db *gorm.DB) ([]entity.OA, map[string]map[int]map[string]float64, error) {
+	oas, err := repository.GetAllOA(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get all oa: %w", err)
+	}
+	rows, err := repository.GetAllOAProductAllocation(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get all oa product allocation: %w", err)
+	}
+	oas, bucketAllocations := setOAAllocations(oas, rows)
+	return oas, bucketAllocations, nil
+}
+
+func setOAAllocations(oas []entity.OA, rows []entity.OAProductAllocation) ([]entity.OA, map[string]map[int]map[string]float64) {
+	oaAllocations := make(map[string]map[string]float64)
+	bucketAllocations := make(map[string]map[int]map[string]float64)
+	for _, row := range rows {
+		if row.BucketID == nil || !row.BucketID.Valid {
+			if _, ok := oaAllocations[row.OAId]; !ok {
+				oaAllocations[row.OAId] = make(map[string]float64)
+			}
+			oaAllocations[row.OAId][row.ProductType] = row.Percentage
+			continue
+		}
+		bucketID := int(row.BucketID.Int32)
+		if _, ok := bucketAllocations[row.OAId]; !ok {
+			bucketAllocations[row.OAId] = make(map[int]map[string]float64)
+		}
+		if _, ok := bucketAllocations[row.OAId][bucketID]; !ok {
+			bucketAllocations[row.OAId][bucketID] = make(map[string]float64)
+		}
+		bucketAllocations[row.OAId][bucketID][row.ProductType] = row.Percentage
+	}
+
+	for i, oa := range oas {
+		allocations := make(map[string]float64)
+		if oa.C2CPercentage != nil && oa.C2CPercentage.Valid {
+			allocations[constant.PRODUCT_TYPE_C2C] = oa.C2CPercentage.Float64
+		}
+		if oa.CRLPercentage != nil && oa.CRLPercentage.Valid {
+			allocations[constant.PRODUCT_TYPE_CRL] = oa.CRLPercentage.Float64
+		}
+		for productType, percentage := range oaAllocations[oa.OAId] {
+			allocations[productType] = percentage
+		}
+		oas[i].Allocations = allocations
+	}
+	return oas, bucketAllocations
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"nhj-poc/constant"
+	"nhj-poc/domain/entity"
+	"slices"
+)
+
+type productTypeStrategy struct{}
+
+func init() {
+	RegisterAssignmentStrategy(productTypeStrategy{})
+}
+
+func (productTypeStrategy) AssignBy() string {
+	return constant.ASSIGN_BY_PRODUCT_TYPE
+}
+
+func (productTypeStrategy) Description() string {
+	return "Round-robin over the OAs of each product, within each OA's product percentage"
+}
+
+func (productTypeStrategy) AssignBucket(input BucketInput) BucketResult {
+	return BucketResult{
+		Assignments: assignBucketByProductType(input.Accounts, input.OAs, input.Capacity),
+	}
+}
+
+// assignBucketByProductType keeps one round-robin queue of OAs per product
+// type. An OA leaves a queue when its product capacity runs out and every
+// queue when its total capacity does.
+func assignBucketByProductType(accounts []entity.Account, oas []entity.OA, capacityOA map[string]CapacityOA) []entity.Assignments {
+	queues := make(map[string][]string)
+	for _, oa := range oas {
+		capacity, ok := capacityOA[oa.OAId]
+		if !ok || capacity.Capacity <= 0 {
+			continue
+		}
+		for productType, percentage := range oa.Allocations {
+			if percentage > 0 && capacity.ProductCapacity[productType] > 0 {
+				queues[productType] = append(queues[productType], oa.OAId)
+			}
+		}
+	}
+
+	var assignments []entity.Assignments
+	productType := constant.ASSIGN_BY_PRODUCT_TYPE
+	for _, account := range accounts {
+		accountProductType := account.ProductType.String
+		queue := queues[accountProductType]
+		next := slices.IndexFunc(queue, func(oaID string) bool {
+			return hasProductCapacity(capacityOA[oaID], account)
+		})
+		if next < 0 {
+			continue
+		}
+		assignOaID := queue[next]
+		queues[accountProductType] = slices.Delete(queue, next, next+1)
+		takeCapacity(capacityOA, assignOaID, account)
+		if capacityOA[assignOaID].Capacity > 0 {
+			if capacityOA[assignOaID].ProductCapacity[accountProductType] > 0 {
+				queues[accountProductType] = append(queues[accountProductType], assignOaID)
+			}
+		} else {
+			for otherProductType, otherQueue := range queues {
+				queues[otherProductType] = slices.DeleteFunc(otherQueue, func(oaID string) bool {
+					return oaID == assignOaID
+				})
+			}
+		}
+		assignments = append(assignments, entity.Assignments{
+			AccountID: ToNullString(&account.AccountID),
+			OaID:      ToNullString(&assignOaID),
+			AssignBy:  ToNullString(&productType),
+		})
+	}
+
+	return assignments
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"math"
+	"nhj-poc/constant"
+	"nhj-poc/domain/entity"
+	"sort"
+)
+
+type rankingStrategy struct{}
+
+func init() {
+	RegisterAssignmentStrategy(rankingStrategy{})
+}
+
+func (rankingStrategy) AssignBy() string {
+	return constant.ASSIGN_BY_RANKING
+}
+
+func (rankingStrategy) Description() string {
+	return "Best ranked OA takes the highest outstanding accounts first, within each OA's product percentage"
+}
+
+func (rankingStrategy) AssignBucket(input BucketInput) BucketResult {
+	return BucketResult{
+		Assignments: assignBucketByRanking(input.Accounts, input.OAs, input.Capacity),
+	}
+}
+
+// assignBucketByRanking lets the best ranked OA (lowest ranking) take the
+// highest outstanding accounts until its product capacity is used up, then
+// moves on to the next OA. Accounts arrive sorted by outstanding_amount desc.
+func assignBucketByRanking(accounts []entity.Account, oas []entity.OA, capacityOA map[string]CapacityOA) []entity.Assignments {
+	rankedOAs := make([]entity.OA, len(oas))
+	copy(rankedOAs, oas)
+	sort.SliceStable(rankedOAs, func(i, j int) bool {
+		return getRanking(rankedOAs[i]) < getRanking(rankedOAs[j])
+	})
+
+	var assignments []entity.Assignments
+	ranking := constant.ASSIGN_BY_RANKING
+	for _, account := range accounts {
+		var assignOaID string = ""
+		for _, oa := range rankedOAs {
+			if takeCapacity(capacityOA, oa.OAId, account) {
+				assignOaID = oa.OAId
+				break
+			}
+		}
+		if assignOaID == "" {
+			continue
+		}
+		assignments = append(assignments, entity.Assignments{
+			AccountID: ToNullString(&account.AccountID),
+			OaID:      ToNullString(&assignOaID),
+			AssignBy:  ToNullString(&ranking),
+		})
+	}
+
+	return assignments
+}
+
+// getRanking returns the OA ranking, putting OAs without one after every
+// ranked OA.
+func getRanking(oa entity.OA) int {
+	if oa.Ranking == nil || !oa.Ranking.Valid {
+		return math.MaxInt
+	}
+	return int(oa.Ranking.Int16)
+}
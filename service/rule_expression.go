@@ -0,0 +1,428 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"nhj-poc/constant"
+	"nhj-poc/domain/entity"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A rule expression reads like
+//
+//	product_type == "C2C" && days_past_due > 15 && outstanding_amount > 50000 -> oa_group "premium"
+//
+// The condition compares account and customer fields with literals and
+// combines them with &&, ||, ! and parentheses; ! binds tightest, then &&,
+// then ||. The action after -> is either oa_group "<group>" or queue
+// "<queue>".
+//
+// A comparison on an empty field, NULL or blank text, is always false
+// whatever the operator, != included: self_cured != "Y" does not match an
+// account with no self_cured. Negate the comparison instead,
+// !(self_cured == "Y"), to match those accounts too.
+
+type ruleExpression struct {
+	condition ruleNode
+	action    string
+	target    string
+}
+
+func (e *ruleExpression) matches(account entity.Account, customer entity.Customer) bool {
+	return e.condition.evaluate(account, customer)
+}
+
+type ruleFieldType int
+
+const (
+	ruleFieldString ruleFieldType = iota
+	ruleFieldNumber
+)
+
+type ruleValue struct {
+	text   string
+	number float64
+	valid  bool
+}
+
+type ruleField struct {
+	fieldType ruleFieldType
+	get       func(account entity.Account, customer entity.Customer) ruleValue
+}
+
+func stringField(get func(account entity.Account, customer entity.Customer) string) ruleField {
+	return ruleField{
+		fieldType: ruleFieldString,
+		get: func(account entity.Account, customer entity.Customer) ruleValue {
+			value := strings.TrimSpace(get(account, customer))
+			return ruleValue{text: value, valid: value != ""}
+		},
+	}
+}
+
+func numberField(get func(account entity.Account, customer entity.Customer) *sql.NullInt32) ruleField {
+	return ruleField{
+		fieldType: ruleFieldNumber,
+		get: func(account entity.Account, customer entity.Customer) ruleValue {
+			value := get(account, customer)
+			if value == nil || !value.Valid {
+				return ruleValue{}
+			}
+			return ruleValue{number: float64(value.Int32), valid: true}
+		},
+	}
+}
+
+// amountField reads an amount like numberField, but a group lead gives the
+// amount of the whole group.
+func amountField(get func(account entity.Account) *sql.NullInt32, getGroup func(account entity.Account) int64) ruleField {
+	return ruleField{
+		fieldType: ruleFieldNumber,
+		get: func(account entity.Account, customer entity.Customer) ruleValue {
+			if len(account.Members) > 0 {
+				return ruleValue{number: float64(getGroup(account)), valid: true}
+			}
+			value := get(account)
+			if value == nil || !value.Valid {
+				return ruleValue{}
+			}
+			return ruleValue{number: float64(value.Int32), valid: true}
+		},
+	}
+}
+
+var ruleFields = map[string]ruleField{
+	"account_id":           stringField(func(a entity.Account, c entity.Customer) string { return a.AccountID }),
+	"customer_id":          stringField(func(a entity.Account, c entity.Customer) string { return a.CustomerID }),
+	"product_type":         stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(a.ProductType) }),
+	"self_cured":           stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(a.SelfCured) }),
+	"top_up_score":         stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(a.TopUpScore) }),
+	"loss_on_claim":        stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(a.LossOnClaim) }),
+	"early_oa":             stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(a.EarlyOA) }),
+	"outstanding_amount":   amountField(func(a entity.Account) *sql.NullInt32 { return a.OutstandingAmount }, getOutstandingAmount),
+	"overdue_amount":       amountField(func(a entity.Account) *sql.NullInt32 { return a.OverdueAmount }, getOverdueAmount),
+	"days_past_due":        numberField(func(a entity.Account, c entity.Customer) *sql.NullInt32 { return a.DaysPastDue }),
+	"loss_on_sale":         numberField(func(a entity.Account, c entity.Customer) *sql.NullInt32 { return a.LossOnSale }),
+	"customer_name":        stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(c.CustomerName) }),
+	"occupation_id":        numberField(func(a entity.Account, c entity.Customer) *sql.NullInt32 { return c.OccupationID }),
+	"register_tambol":      stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(c.RegisterTambol) }),
+	"register_amphur":      stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(c.RegisterAmphur) }),
+	"register_province":    stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(c.RegisterProvince) }),
+	"register_postal_code": stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(c.RegisterPostalCode) }),
+	"current_tambol":       stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(c.CurrentTambol) }),
+	"current_amphur":       stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(c.CurrentAmphur) }),
+	"current_province":     stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(c.CurrentProvince) }),
+	"current_postal_code":  stringField(func(a entity.Account, c entity.Customer) string { return getNullStringValue(c.CurrentPostalCode) }),
+}
+
+var ruleActions = map[string]string{
+	"oa_group": constant.RULE_ACTION_OA_GROUP,
+	"queue":    constant.RULE_ACTION_QUEUE,
+}
+
+// ruleNode is one node of a parsed condition.
+type ruleNode interface {
+	evaluate(account entity.Account, customer entity.Customer) bool
+}
+
+type ruleAnd struct{ left, right ruleNode }
+
+func (n ruleAnd) evaluate(account entity.Account, customer entity.Customer) bool {
+	return n.left.evaluate(account, customer) && n.right.evaluate(account, customer)
+}
+
+type ruleOr struct{ left, right ruleNode }
+
+func (n ruleOr) evaluate(account entity.Account, customer entity.Customer) bool {
+	return n.left.evaluate(account, customer) || n.right.evaluate(account, customer)
+}
+
+type ruleNot struct{ operand ruleNode }
+
+func (n ruleNot) evaluate(account entity.Account, customer entity.Customer) bool {
+	return !n.operand.evaluate(account, customer)
+}
+
+type ruleComparison struct {
+	field    ruleField
+	operator string
+	literal  ruleValue
+}
+
+func (n ruleComparison) evaluate(account entity.Account, customer entity.Customer) bool {
+	value := n.field.get(account, customer)
+	// NULL compares false with every operator
+	if !value.valid {
+		return false
+	}
+
+	compare := 0
+	if n.field.fieldType == ruleFieldNumber {
+		if value.number < n.literal.number {
+			compare = -1
+		} else if value.number > n.literal.number {
+			compare = 1
+		}
+	} else {
+		compare = strings.Compare(strings.ToUpper(value.text), strings.ToUpper(n.literal.text))
+	}
+
+	switch n.operator {
+	case "==":
+		return compare == 0
+	case "!=":
+		return compare != 0
+	case ">":
+		return compare > 0
+	case ">=":
+		return compare >= 0
+	case "<":
+		return compare < 0
+	case "<=":
+		return compare <= 0
+	}
+	return false
+}
+
+type ruleTokenKind int
+
+const (
+	ruleTokenIdent ruleTokenKind = iota
+	ruleTokenString
+	ruleTokenNumber
+	ruleTokenOperator
+	ruleTokenEnd
+)
+
+type ruleToken struct {
+	kind     ruleTokenKind
+	text     string
+	position int
+}
+
+func tokenizeRule(expression string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenIdent, text: string(runes[start:i]), position: start})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenNumber, text: strings.ReplaceAll(string(runes[start:i]), "_", ""), position: start})
+		case r == '"':
+			start := i
+			i++
+			var text strings.Builder
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				text.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			tokens = append(tokens, ruleToken{kind: ruleTokenString, text: text.String(), position: start})
+		default:
+			operator := ""
+			for _, candidate := range []string{"->", "&&", "||", "==", "!=", ">=", "<=", ">", "<", "!", "(", ")"} {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenOperator, text: operator, position: i})
+			i += len([]rune(operator))
+		}
+	}
+	tokens = append(tokens, ruleToken{kind: ruleTokenEnd, position: len(runes)})
+	return tokens, nil
+}
+
+type ruleParser struct {
+	tokens   []ruleToken
+	position int
+}
+
+// parseRuleExpression parses and type checks a rule expression.
+func parseRuleExpression(expression string) (*ruleExpression, error) {
+	tokens, err := tokenizeRule(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser := &ruleParser{tokens: tokens}
+
+	condition, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !parser.accept(ruleTokenOperator, "->") {
+		return nil, parser.errorf("expected -> before the action")
+	}
+
+	actionToken := parser.next()
+	action, ok := ruleActions[actionToken.text]
+	if actionToken.kind != ruleTokenIdent || !ok {
+		return nil, fmt.Errorf("unknown action %q at position %d, expected oa_group or queue", actionToken.text, actionToken.position)
+	}
+	targetToken := parser.next()
+	if targetToken.kind != ruleTokenString || strings.TrimSpace(targetToken.text) == "" {
+		return nil, fmt.Errorf("expected a quoted target after %s at position %d", actionToken.text, targetToken.position)
+	}
+	if parser.peek().kind != ruleTokenEnd {
+		return nil, parser.errorf("unexpected %q after the action", parser.peek().text)
+	}
+
+	return &ruleExpression{
+		condition: condition,
+		action:    action,
+		target:    strings.TrimSpace(targetToken.text),
+	}, nil
+}
+
+func (p *ruleParser) peek() ruleToken {
+	return p.tokens[p.position]
+}
+
+func (p *ruleParser) next() ruleToken {
+	token := p.tokens[p.position]
+	if token.kind != ruleTokenEnd {
+		p.position++
+	}
+	return token
+}
+
+func (p *ruleParser) accept(kind ruleTokenKind, text string) bool {
+	token := p.peek()
+	if token.kind == kind && token.text == text {
+		p.position++
+		return true
+	}
+	return false
+}
+
+func (p *ruleParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.peek().position)
+}
+
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(ruleTokenOperator, "||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = ruleOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(ruleTokenOperator, "&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = ruleAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseUnary() (ruleNode, error) {
+	if p.accept(ruleTokenOperator, "!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return ruleNot{operand: operand}, nil
+	}
+	if p.accept(ruleTokenOperator, "(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(ruleTokenOperator, ")") {
+			return nil, p.errorf("expected )")
+		}
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *ruleParser) parseComparison() (ruleNode, error) {
+	fieldToken := p.next()
+	if fieldToken.kind != ruleTokenIdent {
+		return nil, fmt.Errorf("expected a field name at position %d", fieldToken.position)
+	}
+	field, ok := ruleFields[fieldToken.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q at position %d", fieldToken.text, fieldToken.position)
+	}
+
+	operatorToken := p.next()
+	if operatorToken.kind != ruleTokenOperator || !isComparisonOperator(operatorToken.text) {
+		return nil, fmt.Errorf("expected a comparison operator after %s at position %d", fieldToken.text, operatorToken.position)
+	}
+
+	literalToken := p.next()
+	var literal ruleValue
+	switch field.fieldType {
+	case ruleFieldNumber:
+		if literalToken.kind != ruleTokenNumber {
+			return nil, fmt.Errorf("%s is a number field, expected a number at position %d", fieldToken.text, literalToken.position)
+		}
+		number, err := strconv.ParseFloat(literalToken.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", literalToken.text, literalToken.position)
+		}
+		literal = ruleValue{number: number, valid: true}
+	case ruleFieldString:
+		if literalToken.kind != ruleTokenString {
+			return nil, fmt.Errorf("%s is a text field, expected a quoted string at position %d", fieldToken.text, literalToken.position)
+		}
+		if operatorToken.text != "==" && operatorToken.text != "!=" {
+			return nil, fmt.Errorf("%s is a text field, only == and != are allowed at position %d", fieldToken.text, operatorToken.position)
+		}
+		literal = ruleValue{text: literalToken.text, valid: true}
+	}
+
+	return ruleComparison{
+		field:    field,
+		operator: operatorToken.text,
+		literal:  literal,
+	}, nil
+}
+
+func isComparisonOperator(operator string) bool {
+	switch operator {
+	case "==", "!=", ">", ">=", "<", "<=":
+		return true
+	}
+	return false
+}
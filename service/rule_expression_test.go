@@ -0,0 +1,172 @@
+package service
+
+import (
+	"database/sql"
+	"nhj-poc/constant"
+	"nhj-poc/domain/entity"
+	"strings"
+	"testing"
+)
+
+func newRuleAccount(productType string, daysPastDue int32, outstanding int32) entity.Account {
+	return entity.Account{
+		AccountID:         "A1",
+		CustomerID:        "C1",
+		ProductType:       &sql.NullString{String: productType, Valid: true},
+		DaysPastDue:       &sql.NullInt32{Int32: daysPastDue, Valid: true},
+		OutstandingAmount: &sql.NullInt32{Int32: outstanding, Valid: true},
+	}
+}
+
+func TestParseRuleExpressionAction(t *testing.T) {
+	tests := []struct {
+		expression string
+		action     string
+		target     string
+	}{
+		{`product_type == "C2C" -> oa_group "premium"`, constant.RULE_ACTION_OA_GROUP, "premium"},
+		{`days_past_due > 90 -> queue " legal "`, constant.RULE_ACTION_QUEUE, "legal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			rule, err := parseRuleExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("parseRuleExpression() error = %v", err)
+			}
+			if rule.action != tt.action || rule.target != tt.target {
+				t.Errorf("parseRuleExpression() = %s %q, want %s %q", rule.action, rule.target, tt.action, tt.target)
+			}
+		})
+	}
+}
+
+func TestRuleExpressionPrecedence(t *testing.T) {
+	c2c := newRuleAccount("C2C", 10, 1000)
+	crl := newRuleAccount("CRL", 10, 1000)
+
+	tests := []struct {
+		name      string
+		condition string
+		account   entity.Account
+		want      bool
+	}{
+		// && binds tighter than ||: C2C || (CRL && dpd > 100)
+		{"and before or, left side", `product_type == "C2C" || product_type == "CRL" && days_past_due > 100`, c2c, true},
+		{"and before or, right side", `product_type == "C2C" || product_type == "CRL" && days_past_due > 100`, crl, false},
+		{"parentheses first", `(product_type == "C2C" || product_type == "CRL") && days_past_due > 100`, c2c, false},
+		// ! binds tighter than &&: (!C2C) && dpd > 5
+		{"not before and", `!product_type == "C2C" && days_past_due > 5`, c2c, false},
+		{"not before and, other product", `!product_type == "C2C" && days_past_due > 5`, crl, true},
+		{"not on a group", `!(product_type == "C2C" && days_past_due > 5)`, c2c, false},
+		{"double not", `!!(product_type == "C2C")`, c2c, true},
+		{"or is left to right", `days_past_due < 0 || days_past_due > 100 || outstanding_amount >= 1000`, c2c, true},
+		{"text is case insensitive", `product_type == "c2c"`, c2c, true},
+		{"negative number", `days_past_due > -1`, c2c, true},
+		{"number with separators", `outstanding_amount < 1_000_000`, c2c, true},
+		{"boundary", `days_past_due >= 10 && days_past_due <= 10`, c2c, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := parseRuleExpression(tt.condition + ` -> oa_group "g"`)
+			if err != nil {
+				t.Fatalf("parseRuleExpression() error = %v", err)
+			}
+			if got := rule.matches(tt.account, entity.Customer{}); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleExpressionNull(t *testing.T) {
+	account := entity.Account{
+		AccountID:   "A1",
+		ProductType: &sql.NullString{String: "C2C", Valid: true},
+		DaysPastDue: &sql.NullInt32{Valid: false},
+		SelfCured:   &sql.NullString{String: "  ", Valid: true},
+	}
+
+	tests := []struct {
+		name      string
+		condition string
+		want      bool
+	}{
+		{"null number ==", `days_past_due == 0`, false},
+		{"null number !=", `days_past_due != 0`, false},
+		{"null number >", `days_past_due > -1`, false},
+		{"null number <=", `days_past_due <= 0`, false},
+		{"nil pointer", `outstanding_amount >= 0`, false},
+		{"blank text ==", `self_cured == ""`, false},
+		{"blank text !=", `self_cured != "Y"`, false},
+		{"nil text !=", `top_up_score != "A"`, false},
+		{"empty customer field", `customer_name != "x"`, false},
+		{"negated comparison matches", `!(self_cured == "Y")`, true},
+		{"null inside or", `days_past_due > 0 || product_type == "C2C"`, true},
+		{"null inside and", `days_past_due > 0 && product_type == "C2C"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := parseRuleExpression(tt.condition + ` -> oa_group "g"`)
+			if err != nil {
+				t.Fatalf("parseRuleExpression() error = %v", err)
+			}
+			if got := rule.matches(account, entity.Customer{}); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleExpressionGroupAmount(t *testing.T) {
+	lead := newRuleAccount("C2C", 10, 1000)
+	lead.Members = []entity.Account{newRuleAccount("C2C", 5, 2_000_000_000)}
+	lead.GroupOutstanding = 2_000_001_000
+
+	rule, err := parseRuleExpression(`outstanding_amount > 2000000000 -> oa_group "g"`)
+	if err != nil {
+		t.Fatalf("parseRuleExpression() error = %v", err)
+	}
+	if !rule.matches(lead, entity.Customer{}) {
+		t.Error("matches() = false for a group lead, want the group outstanding compared")
+	}
+}
+
+func TestParseRuleExpressionMalformed(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    string
+	}{
+		{"empty", ``, "expected a field name"},
+		{"no action", `days_past_due > 5`, "expected -> before the action"},
+		{"unknown field", `colour == "red" -> queue "q"`, `unknown field "colour"`},
+		{"unknown action", `days_past_due > 5 -> send "q"`, `unknown action "send"`},
+		{"missing target", `days_past_due > 5 -> queue`, "expected a quoted target"},
+		{"blank target", `days_past_due > 5 -> queue "  "`, "expected a quoted target"},
+		{"unquoted target", `days_past_due > 5 -> queue legal`, "expected a quoted target"},
+		{"trailing tokens", `days_past_due > 5 -> queue "q" extra`, `unexpected "extra"`},
+		{"missing operator", `days_past_due 5 -> queue "q"`, "expected a comparison operator"},
+		{"missing literal", `days_past_due > -> queue "q"`, "expected a number"},
+		{"text for number field", `days_past_due > "5" -> queue "q"`, "is a number field"},
+		{"number for text field", `product_type == 5 -> queue "q"`, "is a text field"},
+		{"ordering on text", `product_type > "C2C" -> queue "q"`, "only == and != are allowed"},
+		{"invalid number", `days_past_due > 1.2.3 -> queue "q"`, "invalid number"},
+		{"unterminated string", `product_type == "C2C -> queue q`, "unterminated string"},
+		{"unclosed parenthesis", `(days_past_due > 5 -> queue "q"`, "expected )"},
+		{"stray parenthesis", `days_past_due > 5) -> queue "q"`, "expected -> before the action"},
+		{"dangling and", `days_past_due > 5 && -> queue "q"`, "expected a field name"},
+		{"single equals", `days_past_due = 5 -> queue "q"`, "unexpected character '='"},
+		{"unknown character", `days_past_due > 5 # -> queue "q"`, "unexpected character '#'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRuleExpression(tt.expression)
+			if err == nil {
+				t.Fatalf("parseRuleExpression(%q) error = nil, want %q", tt.expression, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseRuleExpression(%q) error = %q, want it to contain %q", tt.expression, err, tt.wantErr)
+			}
+		})
+	}
+}
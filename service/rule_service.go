@@ -0,0 +1,198 @@
+package service
+
+import (
+	"fmt"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"nhj-poc/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func GetRoutingRules() ([]entity.RoutingRule, error) {
+	rules, err := repository.GetAllRoutingRule(database.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all routing rule: %w", err)
+	}
+	return rules, nil
+}
+
+func CreateRoutingRule(rModel model.RoutingRule) (*entity.RoutingRule, error) {
+	rule := toRoutingRuleEntity(rModel)
+	if err := validateRoutingRule(&rule); err != nil {
+		return nil, err
+	}
+	if err := database.DB.Create(&rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to insert routing rule: %w", err)
+	}
+	return &rule, nil
+}
+
+func UpdateRoutingRule(ruleID int, rModel model.RoutingRule) (*entity.RoutingRule, error) {
+	if _, err := repository.GetRoutingRuleByRuleID(database.DB, ruleID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("rule_id %d not found", ruleID)
+		}
+		return nil, err
+	}
+
+	rule := toRoutingRuleEntity(rModel)
+	rule.RuleID = ruleID
+	if err := validateRoutingRule(&rule); err != nil {
+		return nil, err
+	}
+	if err := database.DB.
+		Model(&entity.RoutingRule{}).
+		Where("rule_id = ?", ruleID).
+		Select("*").
+		Updates(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to update routing rule %d: %w", ruleID, err)
+	}
+	return &rule, nil
+}
+
+func DeleteRoutingRule(ruleID int) error {
+	result := database.DB.Where("rule_id = ?", ruleID).Delete(&entity.RoutingRule{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete routing rule %d: %w", ruleID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("rule_id %d not found", ruleID)
+	}
+	return nil
+}
+
+func toRoutingRuleEntity(rModel model.RoutingRule) entity.RoutingRule {
+	var expression *string
+	if rModel.Expression != nil {
+		trimmed := strings.TrimSpace(*rModel.Expression)
+		expression = &trimmed
+	}
+	return entity.RoutingRule{
+		RuleName:   strings.TrimSpace(rModel.RuleName),
+		Priority:   rModel.Priority,
+		Field:      rModel.Field,
+		Operator:   rModel.Operator,
+		Value:      strings.TrimSpace(rModel.Value),
+		Expression: ToNullString(expression),
+		Action:     rModel.Action,
+		Target:     strings.TrimSpace(rModel.Target),
+		Active:     rModel.Active,
+	}
+}
+
+// validateRoutingRule checks a flag rule, or parses an expression rule and
+// fills its action and target from the expression.
+func validateRoutingRule(rule *entity.RoutingRule) error {
+	if rule.RuleName == "" {
+		return fmt.Errorf("rule_name is required")
+	}
+	if expression := getNullStringValue(rule.Expression); expression != "" {
+		if rule.Field != "" || rule.Operator != "" || rule.Value != "" {
+			return fmt.Errorf("field, operator and value must be empty when expression is set")
+		}
+		parsed, err := parseRuleExpression(expression)
+		if err != nil {
+			return fmt.Errorf("invalid expression: %w", err)
+		}
+		rule.Action = parsed.action
+		rule.Target = parsed.target
+		return nil
+	}
+
+	switch rule.Field {
+	case constant.RULE_FIELD_EARLY_OA, constant.RULE_FIELD_SELF_CURED,
+		constant.RULE_FIELD_TOP_UP_SCORE, constant.RULE_FIELD_LOSS_ON_CLAIM:
+	default:
+		return fmt.Errorf("field %q is not supported", rule.Field)
+	}
+	if rule.Operator != constant.RULE_OPERATOR_EQUAL && rule.Operator != constant.RULE_OPERATOR_NOT_EQUAL {
+		return fmt.Errorf("operator must be %s or %s", constant.RULE_OPERATOR_EQUAL, constant.RULE_OPERATOR_NOT_EQUAL)
+	}
+	if rule.Action != constant.RULE_ACTION_QUEUE && rule.Action != constant.RULE_ACTION_OA_GROUP {
+		return fmt.Errorf("action must be %s or %s", constant.RULE_ACTION_QUEUE, constant.RULE_ACTION_OA_GROUP)
+	}
+	if rule.Target == "" {
+		return fmt.Errorf("target is required")
+	}
+	return nil
+}
+
+// TestRuleExpression evaluates an expression against a sample account and
+// customer without touching the database.
+func TestRuleExpression(expression string, aModel model.Account, cModel model.Customer) (*model.RuleTestResult, error) {
+	parsed, err := parseRuleExpression(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+
+	matched := parsed.matches(ConvertModelToEntityAccount(aModel), ConvertModelToEntityCustomer(cModel))
+	result := &model.RuleTestResult{Matched: matched}
+	if matched {
+		result.Action = parsed.action
+		result.Target = parsed.target
+	}
+	return result, nil
+}
+
+// routingRule is an active rule with its expression parsed once per run.
+type routingRule struct {
+	entity.RoutingRule
+	expression *ruleExpression
+}
+
+func compileRoutingRules(rules []entity.RoutingRule) ([]routingRule, error) {
+	compiled := make([]routingRule, 0, len(rules))
+	for _, rule := range rules {
+		compiledRule := routingRule{RoutingRule: rule}
+		if expression := getNullStringValue(rule.Expression); expression != "" {
+			parsed, err := parseRuleExpression(expression)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expression in rule_id %d: %w", rule.RuleID, err)
+			}
+			compiledRule.expression = parsed
+			compiledRule.Action = parsed.action
+			compiledRule.Target = parsed.target
+		}
+		compiled = append(compiled, compiledRule)
+	}
+	return compiled, nil
+}
+
+// matchRoutingRule returns the first rule, in priority order, the account
+// satisfies.
+func matchRoutingRule(account entity.Account, customer entity.Customer, rules []routingRule) *entity.RoutingRule {
+	for i, rule := range rules {
+		if rule.expression != nil {
+			if rule.expression.matches(account, customer) {
+				return &rules[i].RoutingRule
+			}
+			continue
+		}
+
+		value := strings.TrimSpace(getRuleField(account, rule.Field))
+		equal := strings.EqualFold(value, rule.Value)
+		if (rule.Operator == constant.RULE_OPERATOR_EQUAL && equal) ||
+			(rule.Operator == constant.RULE_OPERATOR_NOT_EQUAL && !equal) {
+			return &rules[i].RoutingRule
+		}
+	}
+	return nil
+}
+
+func getRuleField(account entity.Account, field string) string {
+	switch field {
+	case constant.RULE_FIELD_EARLY_OA:
+		return getNullStringValue(account.EarlyOA)
+	case constant.RULE_FIELD_SELF_CURED:
+		return getNullStringValue(account.SelfCured)
+	case constant.RULE_FIELD_TOP_UP_SCORE:
+		return getNullStringValue(account.TopUpScore)
+	case constant.RULE_FIELD_LOSS_ON_CLAIM:
+		return getNullStringValue(account.LossOnClaim)
+	}
+	return ""
+}
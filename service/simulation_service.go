@@ -0,0 +1,246 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"nhj-poc/repository"
+	"sort"
+	"time"
+)
+
+// CreateAccountSnapshot copies the account table as it is now under
+// snapshotDate, replacing an earlier snapshot of the same date.
+func CreateAccountSnapshot(snapshotDate time.Time) (int, error) {
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	accounts, err := repository.GetAllAccount(tx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get all accounts: %w", err)
+	}
+	if err := repository.DeleteAccountSnapshot(tx, snapshotDate); err != nil {
+		return 0, fmt.Errorf("failed to delete account snapshot: %w", err)
+	}
+
+	snapshot := make([]entity.AccountSnapshot, 0, len(accounts))
+	for _, account := range accounts {
+		snapshot = append(snapshot, entity.AccountSnapshot{
+			SnapshotDate: snapshotDate,
+			Account:      account,
+		})
+	}
+	if len(snapshot) > 0 {
+		if err := tx.CreateInBatches(snapshot, 1000).Error; err != nil {
+			return 0, fmt.Errorf("failed to insert account snapshot batch: %w", err)
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(snapshot), nil
+}
+
+func GetAccountSnapshots() ([]entity.SnapshotSummary, error) {
+	summaries, err := repository.GetSnapshotSummaries(database.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account snapshots: %w", err)
+	}
+	return summaries, nil
+}
+
+// SimulateAssignments replays the allocation on an account snapshot once per
+// candidate configuration. Everything runs in memory; the assignments table
+// is not touched. Overrides, experiments and current assignments belong to
+// today's portfolio, so the simulation leaves them out; routing rules and
+// customers are the stored ones.
+func SimulateAssignments(sModel model.Simulation) (*model.SimulationResult, error) {
+	assignBy := sModel.AssignBy
+	if assignBy == "" {
+		assignBy = constant.ASSIGN_BY_PRODUCT_TYPE
+	}
+	if _, err := GetAssignmentStrategy(assignBy); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := repository.GetAccountSnapshot(database.DB, sModel.SnapshotDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account snapshot: %w", err)
+	}
+	if len(snapshot) == 0 {
+		return nil, fmt.Errorf("no account snapshot on %s", sModel.SnapshotDate.Format("2006-01-02"))
+	}
+	accounts := make([]entity.Account, 0, len(snapshot))
+	for _, row := range snapshot {
+		accounts = append(accounts, row.Account)
+	}
+
+	base, err := loadAssignmentData(database.DB)
+	if err != nil {
+		return nil, err
+	}
+	base.overrides = make(map[string]entity.AssignmentOverride)
+	base.experiment = nil
+	base.openAssignments = nil
+	base.currentAssignments = make(map[string]entity.Assignments)
+
+	result := &model.SimulationResult{
+		SnapshotDate: sModel.SnapshotDate.Format("2006-01-02"),
+		AssignBy:     assignBy,
+		AccountCount: len(accounts),
+	}
+	options := model.AssignmentOptions{
+		BalanceOverdue:  sModel.BalanceOverdue,
+		GroupByCustomer: sModel.GroupByCustomer,
+	}
+	for _, config := range sModel.Configurations {
+		data, err := applySimulationConfig(*base, config, accounts)
+		if err != nil {
+			return nil, fmt.Errorf("configuration %q: %w", config.Name, err)
+		}
+		plan, err := planAssignmentData(data, assignBy, options)
+		if err != nil {
+			return nil, fmt.Errorf("configuration %q: %w", config.Name, err)
+		}
+		result.Configurations = append(result.Configurations, getSimulationReport(config.Name, plan))
+	}
+	return result, nil
+}
+
+// applySimulationConfig swaps in the candidate buckets and OAs and resolves
+// the snapshot accounts against them.
+func applySimulationConfig(data assignmentData, config model.SimulationConfig, accounts []entity.Account) (*assignmentData, error) {
+	if len(config.Buckets) > 0 {
+		var buckets []entity.Bucket
+		seen := make(map[int]bool)
+		for _, candidate := range config.Buckets {
+			if candidate.BucketID <= 0 {
+				return nil, fmt.Errorf("bucket_id must be greater than 0")
+			}
+			if seen[candidate.BucketID] {
+				return nil, fmt.Errorf("bucket_id %d is listed twice", candidate.BucketID)
+			}
+			seen[candidate.BucketID] = true
+			buckets = append(buckets, entity.Bucket{
+				BucketID:   candidate.BucketID,
+				BucketName: ToNullString(candidate.BucketName),
+				MinDPD:     candidate.MinDPD,
+				MaxDPD:     candidate.MaxDPD,
+			})
+		}
+		if err := validateBuckets(buckets); err != nil {
+			return nil, err
+		}
+		sort.Slice(buckets, func(i, j int) bool {
+			return buckets[i].MinDPD < buckets[j].MinDPD
+		})
+		data.buckets = buckets
+	}
+
+	if len(config.OAs) > 0 {
+		storedOAs := make(map[string]entity.OA)
+		for _, oa := range data.oas {
+			storedOAs[oa.OAId] = oa
+		}
+
+		var oas []entity.OA
+		seen := make(map[string]bool)
+		oaBucketMap := make(map[string]map[int]entity.OABucket)
+		bucketAllocations := make(map[string]map[int]map[string]float64)
+		for _, candidate := range config.OAs {
+			if seen[candidate.OAId] {
+				return nil, fmt.Errorf("oa_id %s is listed twice", candidate.OAId)
+			}
+			seen[candidate.OAId] = true
+			oas = append(oas, applySimulationOA(storedOAs[candidate.OAId], candidate))
+
+			if len(candidate.Buckets) == 0 {
+				if stored, ok := data.oaBucketMap[candidate.OAId]; ok {
+					oaBucketMap[candidate.OAId] = stored
+				}
+				if stored, ok := data.bucketAllocations[candidate.OAId]; ok {
+					bucketAllocations[candidate.OAId] = stored
+				}
+				continue
+			}
+			bucketAllocations[candidate.OAId] = make(map[int]map[string]float64)
+			for _, bucket := range candidate.Buckets {
+				if _, ok := bucketAllocations[candidate.OAId][bucket.BucketID]; ok {
+					return nil, fmt.Errorf("bucket_id %d is listed twice for oa_id %s", bucket.BucketID, candidate.OAId)
+				}
+				bucketAllocations[candidate.OAId][bucket.BucketID] = bucket.Allocations
+			}
+		}
+		data.oas = oas
+		data.oaBucketMap = oaBucketMap
+		data.bucketAllocations = bucketAllocations
+	}
+
+	data.accountsByBucket, data.accountMap, data.nullDPDAccounts = groupAccountsByBucket(accounts, data.buckets)
+	return &data, nil
+}
+
+func applySimulationOA(oa entity.OA, candidate model.SimulationOA) entity.OA {
+	oa.OAId = candidate.OAId
+	if candidate.Capacity != nil {
+		oa.Capacity = &sql.NullInt16{Int16: int16(*candidate.Capacity), Valid: true}
+	}
+	if candidate.Ranking != nil {
+		oa.Ranking = &sql.NullInt16{Int16: int16(*candidate.Ranking), Valid: true}
+	}
+	allocations := make(map[string]float64)
+	for productType, percentage := range oa.Allocations {
+		allocations[productType] = percentage
+	}
+	for productType, percentage := range candidate.Allocations {
+		allocations[productType] = percentage
+	}
+	oa.Allocations = allocations
+	if candidate.PostalList != nil {
+		oa.PostalList = ToNullString(candidate.PostalList)
+	}
+	if candidate.OAGroup != nil {
+		oa.OAGroup = ToNullString(candidate.OAGroup)
+	}
+	if oa.Capacity == nil {
+		oa.Capacity = &sql.NullInt16{Valid: false}
+	}
+	return oa
+}
+
+func getSimulationReport(name string, plan *assignmentPlan) model.SimulationReport {
+	unassignedCounts := make(map[string]int)
+	for _, account := range plan.unassigned {
+		unassignedCounts[account.Reason]++
+	}
+
+	load := getOALoad(plan)
+	var breaches []model.CapacityBreach
+	for _, oa := range load {
+		if oa.AccountCount > oa.Capacity {
+			breaches = append(breaches, model.CapacityBreach{
+				OAId:         oa.OAId,
+				Capacity:     oa.Capacity,
+				AccountCount: oa.AccountCount,
+				Excess:       oa.AccountCount - oa.Capacity,
+			})
+		}
+	}
+
+	return model.SimulationReport{
+		Name:             name,
+		BucketCounts:     plan.bucketCounts,
+		UnassignedCounts: unassignedCounts,
+		OAs:              load,
+		Balance:          plan.balance,
+		MaxImbalance:     getMaxImbalance(plan.balance),
+		CapacityBreaches: breaches,
+		UnmetDemand:      plan.unmetDemand,
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/repository"
+	"nhj-poc/util"
+	"time"
+)
+
+// slaRecallDays is how long an OA may hold an account with no transaction
+// and no logged contact before the account is taken back.
+var slaRecallDays = loadEnvInt("SLA_RECALL_DAYS", 30)
+
+// RunSLARecall releases the stale assignments and records the job in job_run
+// with the number of released accounts as its unassigned count.
+func RunSLARecall() (*entity.JobRun, error) {
+	jobRun, err := startJobRun(constant.JOB_NAME_SLA_RECALL)
+	if err != nil {
+		return nil, err
+	}
+
+	released, err := releaseStaleAssignments(jobRun.StartedAt, slaRecallDays)
+	if err == nil {
+		jobRun.UnassignedCount = util.IntToNullInt32(released)
+	}
+	return jobRun, finishJobRun(jobRun, err)
+}
+
+// releaseStaleAssignments releases with SLA_EXPIRED every assignment older
+// than days that saw no transaction and no contact by its OA in that time.
+// Manual assignments are left to whoever made them.
+// The accounts go back into the pool for the next assignment run.
+func releaseStaleAssignments(now time.Time, days int) (int, error) {
+	if days <= 0 {
+		return 0, fmt.Errorf("SLA_RECALL_DAYS must be positive")
+	}
+	cutoff := now.AddDate(0, 0, -days)
+
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	// An assignment run in progress finishes first, otherwise it would hand
+	// the released accounts straight back to their OA
+	if err := repository.AdvisoryLock(tx, constant.ASSIGNMENTS_LOCK_KEY); err != nil {
+		return 0, fmt.Errorf("failed to lock assignments: %w", err)
+	}
+
+	stale, err := repository.GetStaleAssignments(tx, cutoff, constant.ASSIGN_BY_MANUAL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stale assignments: %w", err)
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	var assignmentIDs []int
+	previousOA := make(map[string]string)
+	for _, assignment := range stale {
+		assignmentIDs = append(assignmentIDs, assignment.AssignmentsID)
+		previousOA[assignment.AccountID.String] = assignment.OaID.String
+	}
+	if err := repository.ReleaseAssignmentsWithReason(tx, assignmentIDs, now, constant.RELEASE_REASON_SLA_EXPIRED); err != nil {
+		return 0, fmt.Errorf("failed to release stale assignments: %w", err)
+	}
+	changes := getAssignmentChanges(previousOA, map[string]string{}, nil, now)
+	if err := saveAssignmentChanges(tx, changes); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	notifyAssignmentChanges(changes)
+	return len(stale), nil
+}
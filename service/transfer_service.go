@@ -0,0 +1,292 @@
+package service
+
+import (
+	"fmt"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/domain/model"
+	"nhj-poc/repository"
+	"nhj-poc/util"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// transferOverrideDays is how long an approved transfer or recall holds
+// against the assignment runs.
+var transferOverrideDays = loadEnvInt("TRANSFER_OVERRIDE_DAYS", 30)
+
+func GetAssignmentTransfers(status string) ([]entity.AssignmentTransfer, error) {
+	transfers, err := repository.GetAssignmentTransfers(database.DB, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// RequestAssignmentTransfer records a pending recall or transfer of the OA
+// the account is with now.
+func RequestAssignmentTransfer(tModel model.AssignmentTransfer) (*entity.AssignmentTransfer, error) {
+	reason := strings.TrimSpace(tModel.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+
+	open, err := repository.GetOpenAssignment(database.DB, tModel.AccountID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("account_id %s is not assigned to any OA", tModel.AccountID)
+		}
+		return nil, err
+	}
+	fromOaID := getNullStringValue(open.OaID)
+	if fromOaID == "" {
+		return nil, fmt.Errorf("account_id %s is not assigned to any OA", tModel.AccountID)
+	}
+
+	if err := checkTransferOverride(database.DB, tModel.AccountID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	pending, err := repository.TransferExists(database.DB, tModel.AccountID, constant.TRANSFER_STATUS_PENDING)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, fmt.Errorf("account_id %s already has a pending transfer", tModel.AccountID)
+	}
+
+	transferType := constant.TRANSFER_TYPE_RECALL
+	if tModel.ToOaID != nil && *tModel.ToOaID != "" {
+		transferType = constant.TRANSFER_TYPE_TRANSFER
+		if *tModel.ToOaID == fromOaID {
+			return nil, fmt.Errorf("account_id %s is already with OA %s", tModel.AccountID, fromOaID)
+		}
+		exists, err := repository.OAIDExists(database.DB, *tModel.ToOaID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("to_oa_id %s not found", *tModel.ToOaID)
+		}
+	}
+
+	transfer := entity.AssignmentTransfer{
+		AccountID:    tModel.AccountID,
+		TransferType: transferType,
+		FromOaID:     fromOaID,
+		ToOaID:       ToNullString(tModel.ToOaID),
+		Reason:       reason,
+		Status:       constant.TRANSFER_STATUS_PENDING,
+		RequestedBy:  tModel.RequestedBy,
+		RequestedAt:  time.Now(),
+	}
+	if transferType == constant.TRANSFER_TYPE_RECALL {
+		transfer.ToOaID = ToNullString(nil)
+	}
+	if err := database.DB.Create(&transfer).Error; err != nil {
+		return nil, fmt.Errorf("failed to insert assignment transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+// ApproveAssignmentTransfer releases the account from its OA and, for a
+// transfer, opens a new assignment with the receiving OA. The released row
+// stays in the table so the history is kept.
+func ApproveAssignmentTransfer(transferID int, decidedBy string) (*entity.AssignmentTransfer, error) {
+	return decideAssignmentTransfer(transferID, decidedBy, constant.TRANSFER_STATUS_APPROVED)
+}
+
+func RejectAssignmentTransfer(transferID int, decidedBy string) (*entity.AssignmentTransfer, error) {
+	return decideAssignmentTransfer(transferID, decidedBy, constant.TRANSFER_STATUS_REJECTED)
+}
+
+func decideAssignmentTransfer(transferID int, decidedBy string, status string) (*entity.AssignmentTransfer, error) {
+	if decidedBy == "" {
+		return nil, fmt.Errorf("decided_by is required")
+	}
+
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	if err := repository.AdvisoryLock(tx, constant.ASSIGNMENTS_LOCK_KEY); err != nil {
+		return nil, fmt.Errorf("failed to lock assignments: %w", err)
+	}
+
+	transfer, err := repository.GetAssignmentTransferByTransferID(tx, transferID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("transfer_id %d not found", transferID)
+		}
+		return nil, err
+	}
+	if transfer.Status != constant.TRANSFER_STATUS_PENDING {
+		return nil, fmt.Errorf("transfer_id %d is already %s", transferID, transfer.Status)
+	}
+	if strings.EqualFold(transfer.RequestedBy, decidedBy) {
+		return nil, fmt.Errorf("transfer_id %d must be decided by someone other than the requester", transferID)
+	}
+
+	now := time.Now()
+	var changes []entity.AssignmentChange
+	if status == constant.TRANSFER_STATUS_APPROVED {
+		changes, err = applyAssignmentTransfer(tx, transfer, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	transfer.Status = status
+	transfer.DecidedAt = &now
+	transfer.DecidedBy = ToNullString(&decidedBy)
+	if err := tx.
+		Model(&entity.AssignmentTransfer{}).
+		Where("transfer_id = ?", transferID).
+		Updates(map[string]interface{}{
+			"status":     transfer.Status,
+			"decided_at": transfer.DecidedAt,
+			"decided_by": transfer.DecidedBy,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update assignment transfer %d: %w", transferID, err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	notifyAssignmentChanges(changes)
+	return transfer, nil
+}
+
+func applyAssignmentTransfer(tx *gorm.DB, transfer *entity.AssignmentTransfer, now time.Time) ([]entity.AssignmentChange, error) {
+	open, err := repository.GetOpenAssignment(tx, transfer.AccountID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	if open == nil || getNullStringValue(open.OaID) != transfer.FromOaID {
+		return nil, fmt.Errorf("account_id %s is no longer with OA %s", transfer.AccountID, transfer.FromOaID)
+	}
+	// The override may have been set after the request
+	if err := checkTransferOverride(tx, transfer.AccountID, now); err != nil {
+		return nil, err
+	}
+
+	releaseReason := constant.RELEASE_REASON_RECALL
+	toOaID := ""
+	if transfer.TransferType == constant.TRANSFER_TYPE_TRANSFER {
+		releaseReason = constant.RELEASE_REASON_TRANSFER
+		toOaID = getNullStringValue(transfer.ToOaID)
+		if err := checkTransferCapacity(tx, toOaID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := repository.ReleaseAssignmentsWithReason(tx, []int{open.AssignmentsID}, now, releaseReason); err != nil {
+		return nil, fmt.Errorf("failed to release assignment of account %s: %w", transfer.AccountID, err)
+	}
+	if toOaID != "" {
+		assignBy := constant.ASSIGN_BY_TRANSFER
+		if err := saveAssignments(tx, []entity.Assignments{{
+			AccountID:  ToNullString(&transfer.AccountID),
+			OaID:       ToNullString(&toOaID),
+			AssignBy:   ToNullString(&assignBy),
+			AssignedAt: &now,
+		}}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := saveTransferOverride(tx, transfer, now); err != nil {
+		return nil, err
+	}
+
+	changes := getAssignmentChanges(
+		map[string]string{transfer.AccountID: transfer.FromOaID},
+		map[string]string{transfer.AccountID: toOaID},
+		nil, now)
+	if err := saveAssignmentChanges(tx, changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// checkTransferOverride refuses to move an account held by an override set
+// outside the transfer workflow, a LOCK above all.
+func checkTransferOverride(db *gorm.DB, accountID string, now time.Time) error {
+	override, err := repository.GetActiveOverride(db, accountID, now)
+	if err != nil {
+		return fmt.Errorf("failed to get active override of account %s: %w", accountID, err)
+	}
+	return validateTransferOverride(accountID, override)
+}
+
+// validateTransferOverride lets a transfer go ahead when the account has no
+// active override or only one an earlier transfer created, which the new
+// transfer replaces.
+func validateTransferOverride(accountID string, override *entity.AssignmentOverride) error {
+	if override == nil || (override.TransferID != nil && override.TransferID.Valid) {
+		return nil
+	}
+	if override.OverrideType == constant.OVERRIDE_TYPE_LOCK {
+		return fmt.Errorf("account_id %s is locked by override %d", accountID, override.OverrideID)
+	}
+	return fmt.Errorf("account_id %s has an active %s override %d, remove it first", accountID, override.OverrideType, override.OverrideID)
+}
+
+// saveTransferOverride keeps the next assignment runs from undoing the
+// decision: a transferred account is pinned to its new OA and a recalled one
+// is excluded from the OA it left. An override an earlier transfer left is
+// ended; checkTransferOverride has refused any other.
+func saveTransferOverride(tx *gorm.DB, transfer *entity.AssignmentTransfer, now time.Time) error {
+	if err := repository.ExpireTransferOverrides(tx, transfer.AccountID, now); err != nil {
+		return fmt.Errorf("failed to end overrides of account %s: %w", transfer.AccountID, err)
+	}
+
+	expiresAt := now.AddDate(0, 0, transferOverrideDays)
+	override := entity.AssignmentOverride{
+		AccountID:    transfer.AccountID,
+		OverrideType: constant.OVERRIDE_TYPE_EXCLUDE,
+		OaID:         ToNullString(&transfer.FromOaID),
+		Reason:       fmt.Sprintf("%s %d: %s", strings.ToLower(transfer.TransferType), transfer.TransferID, transfer.Reason),
+		ExpiresAt:    &expiresAt,
+		CreatedAt:    now,
+		TransferID:   util.IntToNullInt32(transfer.TransferID),
+	}
+	if transfer.TransferType == constant.TRANSFER_TYPE_TRANSFER {
+		override.OverrideType = constant.OVERRIDE_TYPE_PIN
+		override.OaID = transfer.ToOaID
+	}
+	if err := tx.Create(&override).Error; err != nil {
+		return fmt.Errorf("failed to insert override of account %s: %w", transfer.AccountID, err)
+	}
+	return nil
+}
+
+// checkTransferCapacity refuses a transfer the receiving OA has no room for.
+// The OA row stays locked until the transaction ends so two approvals cannot
+// both take its last place.
+func checkTransferCapacity(tx *gorm.DB, oaID string) error {
+	var oa entity.OA
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&oa, "oa_id = ?", oaID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("to_oa_id %s not found", oaID)
+		}
+		return err
+	}
+	held, err := repository.CountOpenAssignmentsByOA(tx, oaID)
+	if err != nil {
+		return fmt.Errorf("failed to count assignments of OA %s: %w", oaID, err)
+	}
+	capacity := int64(0)
+	if oa.Capacity != nil && oa.Capacity.Valid {
+		capacity = int64(oa.Capacity.Int16)
+	}
+	if held >= capacity {
+		return fmt.Errorf("OA %s is at capacity (%d of %d accounts)", oaID, held, capacity)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"database/sql"
+	"nhj-poc/constant"
+	"nhj-poc/domain/entity"
+	"nhj-poc/util"
+	"strings"
+	"testing"
+)
+
+func TestValidateTransferOverride(t *testing.T) {
+	oaID := &sql.NullString{String: "OA1", Valid: true}
+
+	tests := []struct {
+		name     string
+		override *entity.AssignmentOverride
+		wantErr  string
+	}{
+		{name: "no override"},
+		{
+			name:     "locked by a supervisor",
+			override: &entity.AssignmentOverride{OverrideID: 7, OverrideType: constant.OVERRIDE_TYPE_LOCK},
+			wantErr:  "is locked by override 7",
+		},
+		{
+			name:     "pinned by a supervisor",
+			override: &entity.AssignmentOverride{OverrideID: 8, OverrideType: constant.OVERRIDE_TYPE_PIN, OaID: oaID},
+			wantErr:  "active PIN override 8",
+		},
+		{
+			name:     "pinned by a supervisor, transfer_id null",
+			override: &entity.AssignmentOverride{OverrideID: 9, OverrideType: constant.OVERRIDE_TYPE_PIN, OaID: oaID, TransferID: &sql.NullInt32{Valid: false}},
+			wantErr:  "active PIN override 9",
+		},
+		{
+			name:     "pinned by an earlier transfer",
+			override: &entity.AssignmentOverride{OverrideID: 10, OverrideType: constant.OVERRIDE_TYPE_PIN, OaID: oaID, TransferID: util.IntToNullInt32(3)},
+		},
+		{
+			name:     "excluded by an earlier recall",
+			override: &entity.AssignmentOverride{OverrideID: 11, OverrideType: constant.OVERRIDE_TYPE_EXCLUDE, OaID: oaID, TransferID: util.IntToNullInt32(4)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTransferOverride("A1", tt.override)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateTransferOverride() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateTransferOverride() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
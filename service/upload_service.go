@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"mime/multipart"
+	"nhj-poc/constant"
 	"nhj-poc/database"
 	"nhj-poc/domain/entity"
 	"nhj-poc/domain/model"
@@ -28,7 +29,13 @@ func ProcessExcelUpload(c *gin.Context) error {
 	}
 	defer openedFile.Close()
 
-	return parseAndInsertExcel(openedFile)
+	// The upload is recorded as a job run so the scheduled assignment run
+	// can wait for it
+	jobRun, err := startJobRun(constant.JOB_NAME_EXCEL_UPLOAD)
+	if err != nil {
+		return err
+	}
+	return finishJobRun(jobRun, parseAndInsertExcel(openedFile))
 }
 
 func parseAndInsertExcel(file multipart.File) error {
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"nhj-poc/constant"
+	"nhj-poc/database"
+	"nhj-poc/domain/entity"
+	"nhj-poc/repository"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const worklistSheet = "Worklist"
+
+var worklistHeader = []interface{}{
+	"Account ID", "Customer ID", "Customer Name", "Occupation", "Product Type",
+	"DPD", "Outstanding Amount", "Overdue Amount",
+	"Payment Plan", "Payment Due Date", "Payment Status",
+	"Current Address", "Current Tambol", "Current Amphur", "Current Province", "Current Postal Code",
+	"Register Address", "Register Tambol", "Register Amphur", "Register Province", "Register Postal Code",
+	"Assigned At", "Distance (km)",
+}
+
+// GetOAWorklist builds the Excel worklist of the accounts the OA holds.
+func GetOAWorklist(oaID string) ([]byte, error) {
+	exists, err := repository.OAIDExists(database.DB, oaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check oa_id %s: %w", oaID, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("oa_id %s not found", oaID)
+	}
+
+	rows, err := repository.GetWorklist(database.DB, []string{oaID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worklist of OA %s: %w", oaID, err)
+	}
+	return buildWorklistFile(rows)
+}
+
+// GetAllOAWorklists zips one worklist file per OA, named <oa_id>_worklist.xlsx.
+func GetAllOAWorklists() ([]byte, error) {
+	oas, err := repository.GetAllOA(database.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all oa: %w", err)
+	}
+	rows, err := repository.GetWorklist(database.DB, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worklists: %w", err)
+	}
+	rowsByOA := make(map[string][]entity.WorklistRow)
+	for _, row := range rows {
+		rowsByOA[row.OaID] = append(rowsByOA[row.OaID], row)
+	}
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, oa := range oas {
+		file, err := buildWorklistFile(rowsByOA[oa.OAId])
+		if err != nil {
+			return nil, fmt.Errorf("failed to build worklist of OA %s: %w", oa.OAId, err)
+		}
+		writer, err := archive.Create(oa.OAId + "_worklist.xlsx")
+		if err != nil {
+			return nil, fmt.Errorf("failed to add worklist of OA %s: %w", oa.OAId, err)
+		}
+		if _, err := writer.Write(file); err != nil {
+			return nil, fmt.Errorf("failed to write worklist of OA %s: %w", oa.OAId, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close worklist archive: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+func buildWorklistFile(rows []entity.WorklistRow) ([]byte, error) {
+	xlsx := excelize.NewFile()
+	defer xlsx.Close()
+
+	if err := xlsx.SetSheetName(xlsx.GetSheetName(0), worklistSheet); err != nil {
+		return nil, err
+	}
+	if err := xlsx.SetSheetRow(worklistSheet, "A1", &worklistHeader); err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return nil, err
+		}
+		values := []interface{}{
+			row.AccountID,
+			row.CustomerID,
+			getNullStringValue(row.CustomerName),
+			getNullStringValue(row.OccupationName),
+			getNullStringValue(row.ProductType),
+			getWorklistNumber(row.DaysPastDue),
+			getWorklistNumber(row.OutstandingAmount),
+			getWorklistNumber(row.OverdueAmount),
+			getNullStringValue(row.PaymentTitle),
+			getWorklistDate(row.PaymentDueDate, "2006-01-02"),
+			getPaymentStatusName(row.PaymentStatusID),
+			getNullStringValue(row.CurrentAddress),
+			getNullStringValue(row.CurrentTambol),
+			getNullStringValue(row.CurrentAmphur),
+			getNullStringValue(row.CurrentProvince),
+			getNullStringValue(row.CurrentPostalCode),
+			getNullStringValue(row.RegisterAddress),
+			getNullStringValue(row.RegisterTambol),
+			getNullStringValue(row.RegisterAmphur),
+			getNullStringValue(row.RegisterProvince),
+			getNullStringValue(row.RegisterPostalCode),
+			getWorklistDate(row.AssignedAt, "2006-01-02 15:04"),
+			nil,
+		}
+		if row.DistanceKm != nil && row.DistanceKm.Valid {
+			values[len(values)-1] = row.DistanceKm.Float64
+		}
+		if err := xlsx.SetSheetRow(worklistSheet, cell, &values); err != nil {
+			return nil, err
+		}
+	}
+	if err := xlsx.SetPanes(worklistSheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return nil, err
+	}
+
+	buffer, err := xlsx.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write worklist file: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// getWorklistNumber leaves the cell empty for a null value instead of
+// writing 0.
+func getWorklistNumber(value *sql.NullInt32) interface{} {
+	if value == nil || !value.Valid {
+		return nil
+	}
+	return value.Int32
+}
+
+func getWorklistDate(value *time.Time, layout string) string {
+	if value == nil {
+		return ""
+	}
+	return value.Local().Format(layout)
+}
+
+func getPaymentStatusName(paymentStatusID *sql.NullInt32) string {
+	if paymentStatusID == nil || !paymentStatusID.Valid {
+		return ""
+	}
+	switch paymentStatusID.Int32 {
+	case constant.Normal:
+		return "Normal"
+	case constant.Full:
+		return "Full"
+	case constant.Partial:
+		return "Partial"
+	case constant.Broken:
+		return "Broken"
+	}
+	return ""
+}